@@ -54,18 +54,20 @@ type Drawer struct {
 
 func (me *Drawer) init() bool {
 	me.anim = gfx.NewAnimation(1000)
-	me.box = gfx.LoadImageSize("box.png", 100, 100)
-	font := gfx.LoadFont("LiberationSans-Bold.ttf", 32)
-	if font != nil {
-		font.SetRGB(0, 0, 255)
-		me.text = font.Write("starfish 0.12!")
-		font.Free()
-	} else {
-		fmt.Println("Could not load LiberationSans-Bold.ttf.")
+	font, err := gfx.LoadFont("LiberationSans-Bold.ttf", 32)
+	if err != nil {
+		fmt.Println("Could not load LiberationSans-Bold.ttf:", err)
+		return false
+	}
+	font.SetRGB(0, 0, 255)
+	me.text, err = font.Write("starfish 0.12!")
+	font.Free()
+	if err != nil {
+		fmt.Println("Could not write text:", err)
 		return false
 	}
-	if me.box == nil {
-		fmt.Println("Could not load box.png.")
+	if me.box, err = gfx.LoadImageSize("box.png", 100, 100); err != nil {
+		fmt.Println("Could not load box.png:", err)
 		return false
 	}
 	me.anim.LoadImageSize("box.png", 70, 70)
@@ -118,8 +120,9 @@ func main() {
 	}
 
 	//For a fullscreen at your screens native resolution, simply use this line instead:
-	//if !gfx.OpenDisplay(0, 0, true) {
-	if !gfx.OpenDisplay(800, 600, false) {
+	//if err := gfx.OpenDisplay(0, 0, true); err != nil {
+	if err := gfx.OpenDisplay(800, 600, false); err != nil {
+		fmt.Println("Could not open the display:", err)
 		return
 	}
 	gfx.SetDisplayTitle("starfish example")
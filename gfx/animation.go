@@ -17,10 +17,12 @@ package gfx
 
 import (
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
-var animTicker = time.Now().UnixNano()
+//The time of the last animation tick in nanoseconds, written by the ticking goroutine and read by Animations.
+var animTicker atomic.Int64
 var tickerInterval int64 = 100000000
 
 //Sets how often the clock that Animations update based on is updated.
@@ -28,10 +30,14 @@ func SetAnimTickInterval(ms int64) {
 	tickerInterval = ms
 }
 
+func init() {
+	animTicker.Store(time.Now().UnixNano())
+}
+
 func startAnimTick() {
 	go func() {
-		for running {
-			animTicker = time.Now().UnixNano()
+		for running.Load() {
+			animTicker.Store(time.Now().UnixNano())
 			time.Sleep(time.Duration(tickerInterval))
 		}
 	}()
@@ -70,7 +76,7 @@ func (me *Animation) GetImage() *Image {
 	if me.images == nil {
 		return nil
 	}
-	if t := animTicker; t-me.lastUpdate >= me.interval {
+	if t := animTicker.Load(); t-me.lastUpdate >= me.interval {
 		me.slide += int((t - me.lastUpdate) / me.interval)
 		me.slide %= len(me.images)
 		me.lastUpdate = t
@@ -88,19 +94,26 @@ func (me *Animation) Size() int {
 	return len(me.images)
 }
 
-func (me *Animation) LoadImage(path string) {
-	if i := LoadImage(path); i != nil {
-		me.images = append(me.images, i)
+//Loads the image at the given path and adds it to the end of this Animation.
+func (me *Animation) LoadImage(path string) error {
+	i, err := LoadImage(path)
+	if err != nil {
+		return err
 	}
+	me.images = append(me.images, i)
+	return nil
 }
 
-func (me *Animation) LoadImageSize(path string, width, height int) {
-	if i := LoadImageSize(path, width, height); i != nil {
-		me.images = append(me.images, i)
-		log.Printf("Animatin: Loaded image {path: %s, width: %d, height: %d}", path, width, height)
-	} else {
-		errlog.Printf("Animatin: Could not load image {path: %s, width: %d, height: %d}", path, width, height)
+//Loads the image at the given path at the given size and adds it to the end of this Animation.
+func (me *Animation) LoadImageSize(path string, width, height int) error {
+	i, err := LoadImageSize(path, width, height)
+	if err != nil {
+		logger().Error("Animation: Could not load image", "path", path, "width", width, "height", height, "err", err)
+		return err
 	}
+	me.images = append(me.images, i)
+	logger().Debug("Animation: Loaded image", "path", path, "width", width, "height", height)
+	return nil
 }
 
 //Frees this Animations images, rendering it useless.
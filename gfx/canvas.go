@@ -16,20 +16,71 @@
 package gfx
 
 import (
+	"fmt"
 	starfish "github.com/gtalent/starfish"
 	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"math"
+	"time"
+)
+
+//Rules for which parts of a polygon are inside it, where its edges cross.
+const (
+	//Parts are inside if a line from them crosses the edges an odd number of times.
+	FillRule_EvenOdd int = p.FillRule_EvenOdd
+	//Parts are inside if the edges wind around them, where edges going opposite ways cancel out.
+	FillRule_NonZero = p.FillRule_NonZero
+)
+
+//Ways that what a Canvas draws can be blended into what is under it.
+const (
+	//Blends by the alpha of what is drawn. The default.
+	BlendMode_Alpha int = p.BlendMode_Alpha
+	//Adds the color of what is drawn, scaled by its alpha, to what is under it, for glows and particles.
+	BlendMode_Add = p.BlendMode_Add
+	//Multiplies what is under by the color of what is drawn, ignoring its alpha.
+	BlendMode_Modulate = p.BlendMode_Modulate
+	//Multiplies what is under by the color of what is drawn, fading by its alpha.
+	BlendMode_Multiply = p.BlendMode_Multiply
+	//Brightens what is under by the inverse of the color of what is drawn, the opposite of multiplying.
+	BlendMode_Screen = p.BlendMode_Screen
+	//Replaces what is under with what is drawn, alpha and all, which is fastest for opaque backgrounds.
+	BlendMode_None = p.BlendMode_None
 )
 
 //Used to draw and to hold data for the drawing context.
 type Canvas struct {
 	viewport    viewport
 	color       Color
+	paint       Paint
+	lineWidth   int
+	antialias   bool
+	fillRule    int
+	blendMode   int
+	transform   affine
+	states      []canvasState
 	translation starfish.Point
 	origin      starfish.Point
+	//when end last reported saves or viewports left unbalanced
+	lastWarn time.Time
+}
+
+//The drawing state of a Canvas, as kept by Save for Restore.
+type canvasState struct {
+	color     Color
+	paint     Paint
+	lineWidth int
+	antialias bool
+	fillRule  int
+	blendMode int
+	transform affine
+	viewports uint
 }
 
 func newCanvas() (p Canvas) {
 	p.viewport = newViewport()
+	p.lineWidth = 1
+	p.transform = identity
 	return
 }
 
@@ -38,6 +89,7 @@ func (me *Canvas) load() {
 	me.viewport.calcBounds()
 	r := me.viewport.bounds()
 	p.SetClipRect(r.X, r.Y, r.Width, r.Height)
+	p.SetBlendMode(me.blendMode)
 }
 
 //Returns the bounds of this Canvas
@@ -61,14 +113,6 @@ func (me *Canvas) PopViewport() {
 		me.origin.SubtractFrom(me.viewport.translate())
 		me.viewport.pop()
 		r := me.viewport.bounds()
-
-		if r.Width == -1 {
-			r.Width = p.DisplayWidth()
-		}
-		if r.Height == -1 {
-			r.Height = p.DisplayHeight()
-		}
-
 		p.SetClipRect(r.X, r.Y, r.Width, r.Height)
 		me.origin = me.translation.AddOf(me.viewport.bounds().Point)
 		me.origin.AddTo(me.viewport.translate())
@@ -77,36 +121,441 @@ func (me *Canvas) PopViewport() {
 
 //Sets the color that the Canvas will draw with.
 func (me *Canvas) SetRGB(r, g, b byte) {
-	me.color = Color{Red: r, Green: g, Blue: b, Alpha: 255}
+	me.SetColor(Color{Red: r, Green: g, Blue: b, Alpha: 255})
 }
 
 //Sets the color that the Canvas will draw with.
 func (me *Canvas) SetRGBA(r, g, b, a byte) {
-	me.color = Color{Red: r, Green: g, Blue: b, Alpha: a}
+	me.SetColor(Color{Red: r, Green: g, Blue: b, Alpha: a})
 }
 
 //Sets the color that the Canvas will draw with.
 func (me *Canvas) SetColor(color Color) {
 	me.color = color
+	me.paint = nil
+}
+
+//Sets the Paint that the Canvas will fill shapes with. Lines, outlines and strokes are drawn
+//with the last color set, as are fills after the color is set again.
+func (me *Canvas) SetPaint(paint Paint) {
+	switch paint := paint.(type) {
+	case Color:
+		me.SetColor(paint)
+	case *Color:
+		me.SetColor(*paint)
+	default:
+		me.paint = paint
+	}
+}
+
+//Moves everything drawn after by (x, y), in the units of the current transform.
+//Viewports are not transformed, so they still clip to the same part of the display.
+func (me *Canvas) Translate(x, y float64) {
+	me.transform = me.transform.mul(translation(x, y))
+}
+
+//Rotates everything drawn after by the given degrees clockwise, around the current origin.
+func (me *Canvas) Rotate(degrees float64) {
+	me.transform = me.transform.mul(rotation(degrees * math.Pi / 180))
+}
+
+//Scales everything drawn after by the given factors, away from the current origin.
+func (me *Canvas) Scale(sx, sy float64) {
+	me.transform = me.transform.mul(scaling(sx, sy))
+}
+
+//Saves the drawing state to be brought back by Restore: the color or paint, line width, antialiasing,
+//fill rule, blend mode, transform, and the viewport with its clip. Saves nest as deep as needed.
+func (me *Canvas) Save() {
+	me.states = append(me.states, canvasState{
+		color:     me.color,
+		paint:     me.paint,
+		lineWidth: me.lineWidth,
+		antialias: me.antialias,
+		fillRule:  me.fillRule,
+		blendMode: me.blendMode,
+		transform: me.transform,
+		viewports: me.viewport.pt,
+	})
+}
+
+//Brings back the drawing state saved by the last call to Save, popping any viewports pushed since,
+//unless there is no saved state.
+func (me *Canvas) Restore() {
+	n := len(me.states)
+	if n == 0 {
+		return
+	}
+	s := me.states[n-1]
+	me.states = me.states[:n-1]
+	me.color = s.color
+	me.paint = s.paint
+	me.lineWidth = s.lineWidth
+	me.antialias = s.antialias
+	me.fillRule = s.fillRule
+	me.SetBlendMode(s.blendMode)
+	me.transform = s.transform
+	for me.viewport.pt > s.viewports {
+		me.PopViewport()
+	}
+}
+
+//How often end reports a Canvas that keeps being left unbalanced, as its Drawer would otherwise
+//report it every frame.
+const unbalancedWarnInterval = time.Second
+
+//Restores the first state saved and pops the viewports a Drawer left unbalanced when its frame ends,
+//so that the next frame starts from the same state, and reports them.
+func (me *Canvas) end(drawer interface{}) {
+	if len(me.states) != 0 || me.viewport.pt != 0 {
+		if now := time.Now(); now.Sub(me.lastWarn) >= unbalancedWarnInterval {
+			me.lastWarn = now
+			logger().Warn("Canvas saves or viewports left unbalanced", "drawer", fmt.Sprintf("%T", drawer),
+				"saves", len(me.states), "viewports", me.viewport.pt)
+		}
+		if len(me.states) != 0 {
+			me.states = me.states[:1]
+			me.Restore()
+		}
+		for me.viewport.pt != 0 {
+			me.PopViewport()
+		}
+	}
+	//the next Drawer's Canvas sets its own blend mode, but drawing outside of Canvases should not get this one
+	p.SetBlendMode(p.BlendMode_Alpha)
+}
+
+//Returns the transform from this Canvas's coordinates to the display's.
+func (me *Canvas) matrix() affine {
+	return translation(float64(me.origin.X), float64(me.origin.Y)).mul(me.transform)
+}
+
+//Returns the whole pixels from this Canvas's coordinates to the display's,
+//and whether the transform does nothing more than move by them.
+//Shapes are drawn by the backend's primitives when it does, and as Paths otherwise.
+func (me *Canvas) offset() (starfish.Point, bool) {
+	o, ok := me.transform.offset()
+	return o.AddOf(me.origin), ok
+}
+
+//Runs the given fill with the Canvas's paint, if it is not a solid color.
+func (me *Canvas) fill(f func()) {
+	if me.paint == nil {
+		f()
+		return
+	}
+	p.SetPaint(me.paint.image(me.matrix()))
+	f()
+	p.SetPaint(nil)
+}
+
+//Fills the path through the transform with the Canvas's color.
+func (me *Canvas) fillPath(path *Path, rule int) {
+	p.FillPath(path.contours(me.matrix()), rule, me.color.bColor(), me.antialias)
+}
+
+//Sets how shapes, images and text drawn after blend into what is under them. Defaults to BlendMode_Alpha.
+func (me *Canvas) SetBlendMode(mode int) {
+	me.blendMode = mode
+	p.SetBlendMode(mode)
+}
+
+//Sets the width in pixels of the lines the Canvas will draw. Defaults to 1.
+func (me *Canvas) SetLineWidth(width int) {
+	if width < 1 {
+		width = 1
+	}
+	me.lineWidth = width
+}
+
+//Sets whether the Canvas smooths the edges of the lines and curves it draws. Defaults to off.
+//The SDL backend cannot antialias thick lines, arcs, or pies.
+func (me *Canvas) SetAntialias(on bool) {
+	me.antialias = on
+}
+
+//Draws a line from (x1, y1) to (x2, y2) on this Canvas, including both ends.
+func (me *Canvas) DrawLine(x1, y1, x2, y2 int) {
+	if o, ok := me.offset(); ok {
+		p.DrawLine(x1+o.X, y1+o.Y, x2+o.X, y2+o.Y, me.lineWidth, me.color.bColor(), me.antialias)
+		return
+	}
+	//reach half a pixel past the centers of the end pixels, as the backends' lines do,
+	//or half the width around a line with no length
+	a := p.PointF{X: float64(x1) + 0.5, Y: float64(y1) + 0.5}
+	b := p.PointF{X: float64(x2) + 0.5, Y: float64(y2) + 0.5}
+	d, l := unit(sub(b, a))
+	d = scale(d, 0.5)
+	if l == 0 {
+		d = p.PointF{X: float64(me.lineWidth) / 2}
+	}
+	a, b = sub(a, d), add(b, d)
+	path := NewPath()
+	path.MoveTo(a.X, a.Y)
+	path.LineTo(b.X, b.Y)
+	me.StrokePath(path, StrokeOptions{})
+}
+
+//Draws lines connecting the given points in order on this Canvas.
+//Each line is drawn on its own, so translucent lines blend twice where they meet.
+func (me *Canvas) DrawLines(points []starfish.Point) {
+	for i := 1; i < len(points); i++ {
+		me.DrawLine(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y)
+	}
+}
+
+//Sets the rule FillPolygon uses to fill self-intersecting polygons. Defaults to FillRule_EvenOdd.
+func (me *Canvas) SetFillRule(rule int) {
+	me.fillRule = rule
+}
+
+//Fills the polygon with the given corners on this Canvas. Concave and self-intersecting polygons
+//are filled by the Canvas's fill rule.
+func (me *Canvas) FillPolygon(points []starfish.Point) {
+	if o, ok := me.offset(); ok {
+		pts := make([]image.Point, len(points))
+		for i, pt := range points {
+			pts[i] = image.Point{pt.X + o.X, pt.Y + o.Y}
+		}
+		me.fill(func() {
+			p.FillPolygon(pts, me.fillRule, me.color.bColor(), me.antialias)
+		})
+		return
+	}
+	path := NewPath()
+	for _, pt := range points {
+		path.LineTo(float64(pt.X)+0.5, float64(pt.Y)+0.5)
+	}
+	path.Close()
+	me.FillPath(path)
+}
+
+//Draws the outline of the polygon with the given corners on this Canvas.
+func (me *Canvas) DrawPolygon(points []starfish.Point) {
+	if len(points) == 0 {
+		return
+	}
+	me.DrawLines(points)
+	last := points[len(points)-1]
+	me.DrawLine(last.X, last.Y, points[0].X, points[0].Y)
+}
+
+//Fills the given Path on this Canvas by the Canvas's fill rule.
+func (me *Canvas) FillPath(path *Path) {
+	me.fill(func() {
+		me.fillPath(path, me.fillRule)
+	})
+}
+
+//Draws the lines and curves of the given Path on this Canvas.
+func (me *Canvas) StrokePath(path *Path, opts StrokeOptions) {
+	if opts.Width <= 0 {
+		opts.Width = float64(me.lineWidth)
+	}
+	if opts.MiterLimit <= 0 {
+		opts.MiterLimit = 10
+	}
+	//stroke at the transform's scale, so that curves are flattened finely enough for it,
+	//then map the outline through the rest of the transform
+	m := me.matrix()
+	s := m.scale()
+	if s == 0 {
+		return
+	}
+	opts.Width *= s
+	opts.DashOffset *= s
+	dashes := make([]float64, len(opts.Dashes))
+	for i, d := range opts.Dashes {
+		dashes[i] = d * s
+	}
+	opts.Dashes = dashes
+	contours := strokeContours(path.flatten(scaling(s, s)), opts)
+	rest := m.mul(scaling(1/s, 1/s))
+	for _, c := range contours {
+		for i, pt := range c {
+			c[i] = rest.apply(pt)
+		}
+	}
+	p.FillPath(contours, p.FillRule_NonZero, me.color.bColor(), me.antialias)
+}
+
+//Fills a circle centered on (x, y) on this Canvas.
+func (me *Canvas) FillCircle(x, y, radius int) {
+	me.FillEllipse(x, y, radius, radius)
+}
+
+//Draws a 1 pixel wide outline of a circle centered on (x, y) on this Canvas.
+func (me *Canvas) DrawCircle(x, y, radius int) {
+	me.DrawEllipse(x, y, radius, radius)
+}
+
+//Fills an ellipse centered on (x, y) on this Canvas, with the given horizontal and vertical radii.
+func (me *Canvas) FillEllipse(x, y, rx, ry int) {
+	if o, ok := me.offset(); ok {
+		me.fill(func() {
+			p.FillEllipse(x+o.X, y+o.Y, rx, ry, me.color.bColor(), me.antialias)
+		})
+		return
+	}
+	path := NewPath()
+	path.ellipse(float64(x)+0.5, float64(y)+0.5, float64(rx)+0.5, float64(ry)+0.5)
+	me.fill(func() {
+		me.fillPath(path, p.FillRule_NonZero)
+	})
+}
+
+//Draws a 1 pixel wide outline of an ellipse centered on (x, y) on this Canvas,
+//with the given horizontal and vertical radii.
+func (me *Canvas) DrawEllipse(x, y, rx, ry int) {
+	if o, ok := me.offset(); ok {
+		p.DrawEllipse(x+o.X, y+o.Y, rx, ry, me.color.bColor(), me.antialias)
+		return
+	}
+	path := NewPath()
+	path.ellipse(float64(x)+0.5, float64(y)+0.5, float64(rx), float64(ry))
+	me.StrokePath(path, StrokeOptions{Width: 1})
+}
+
+//Returns the clockwise sweep in radians from start to end degrees.
+func sweep(start, end int) float64 {
+	s := math.Mod(float64(end-start), 360)
+	if s < 0 {
+		s += 360
+	}
+	return s * math.Pi / 180
+}
+
+//Draws a 1 pixel wide arc of the circle centered on (x, y) on this Canvas.
+//The arc goes from start to end degrees, clockwise from 0 degrees at the right of the circle.
+func (me *Canvas) DrawArc(x, y, radius, start, end int) {
+	if o, ok := me.offset(); ok {
+		p.DrawArc(x+o.X, y+o.Y, radius, start, end, me.color.bColor(), me.antialias)
+		return
+	}
+	center := p.PointF{X: float64(x) + 0.5, Y: float64(y) + 0.5}
+	r, a := float64(radius), float64(start)*math.Pi/180
+	path := NewPath()
+	path.MoveTo(center.X+r*math.Cos(a), center.Y+r*math.Sin(a))
+	path.arc(center, r, r, a, sweep(start, end))
+	me.StrokePath(path, StrokeOptions{Width: 1})
+}
+
+//Fills the slice of the circle centered on (x, y) on this Canvas from start to end degrees,
+//clockwise from 0 degrees at the right of the circle.
+func (me *Canvas) FillPie(x, y, radius, start, end int) {
+	if o, ok := me.offset(); ok {
+		me.fill(func() {
+			p.FillPie(x+o.X, y+o.Y, radius, start, end, me.color.bColor(), me.antialias)
+		})
+		return
+	}
+	center := p.PointF{X: float64(x) + 0.5, Y: float64(y) + 0.5}
+	r, a := float64(radius)+0.5, float64(start)*math.Pi/180
+	path := NewPath()
+	path.MoveTo(center.X, center.Y)
+	path.LineTo(center.X+r*math.Cos(a), center.Y+r*math.Sin(a))
+	path.arc(center, r, r, a, sweep(start, end))
+	path.Close()
+	me.fill(func() {
+		me.fillPath(path, p.FillRule_NonZero)
+	})
 }
 
 //Fills a rounded rectangle at the given coordinates and size on this Canvas.
 func (me *Canvas) FillRoundedRect(x, y, width, height, radius int) {
-	x += me.origin.X
-	y += me.origin.Y
-	p.FillRoundedRect(x, y, width, height, radius, me.color.bColor())
+	if o, ok := me.offset(); ok {
+		me.fill(func() {
+			p.FillRoundedRect(x+o.X, y+o.Y, width, height, radius, me.color.bColor())
+		})
+		return
+	}
+	path := NewPath()
+	path.roundedRect(float64(x), float64(y), float64(width+1), float64(height+1), float64(radius)+0.5)
+	me.fill(func() {
+		me.fillPath(path, p.FillRule_NonZero)
+	})
+}
+
+//Draws the border of a rounded rectangle on this Canvas, the line width inward from the edges
+//of the same rounded rectangle FillRoundedRect fills.
+func (me *Canvas) DrawRoundedRect(x, y, width, height, radius int) {
+	if o, ok := me.offset(); ok {
+		p.DrawRoundedRect(x+o.X, y+o.Y, width, height, radius, me.lineWidth, me.color.bColor())
+		return
+	}
+	lw := float64(me.lineWidth)
+	w, h, r := float64(width+1), float64(height+1), float64(radius)+0.5
+	path := NewPath()
+	path.roundedRect(float64(x), float64(y), w, h, r)
+	if 2*lw < w && 2*lw < h {
+		path.roundedRect(float64(x)+lw, float64(y)+lw, w-2*lw, h-2*lw, r-lw)
+	}
+	me.fillPath(path, p.FillRule_EvenOdd)
 }
 
 //Fills a rectangle at the given coordinates and size on this Canvas.
 func (me *Canvas) FillRect(x, y, width, height int) {
-	x += me.origin.X
-	y += me.origin.Y
-	p.FillRect(x, y, width, height, me.color.bColor())
+	if o, ok := me.offset(); ok {
+		me.fill(func() {
+			p.FillRect(x+o.X, y+o.Y, width, height, me.color.bColor())
+		})
+		return
+	}
+	path := NewPath()
+	path.rect(float64(x), float64(y), float64(width), float64(height))
+	me.fill(func() {
+		me.fillPath(path, p.FillRule_NonZero)
+	})
+}
+
+//Draws the border of a rectangle on this Canvas, the line width inward from the edges
+//of the same rectangle FillRect fills.
+func (me *Canvas) DrawRect(x, y, width, height int) {
+	lw := me.lineWidth
+	o, ok := me.offset()
+	if !ok {
+		path := NewPath()
+		path.rect(float64(x), float64(y), float64(width), float64(height))
+		if 2*lw < width && 2*lw < height {
+			path.rect(float64(x+lw), float64(y+lw), float64(width-2*lw), float64(height-2*lw))
+		}
+		me.fillPath(path, p.FillRule_EvenOdd)
+		return
+	}
+	x += o.X
+	y += o.Y
+	c := me.color.bColor()
+	if 2*lw >= width || 2*lw >= height {
+		p.FillRect(x, y, width, height, c)
+		return
+	}
+	//the sides fit between the top and bottom, so translucent corners are not drawn twice
+	p.FillRect(x, y, width, lw, c)
+	p.FillRect(x, y+height-lw, width, lw, c)
+	p.FillRect(x, y+lw, lw, height-2*lw, c)
+	p.FillRect(x+width-lw, y+lw, lw, height-2*lw, c)
+}
+
+//Draws the source rect of the texture at (x, y) on this Canvas, scaled to the texture's size.
+func (me *Canvas) drawTexture(t *p.Image, x, y, srcX, srcY, srcW, srcH int) {
+	if o, ok := me.offset(); ok {
+		p.DrawImage(t, x+o.X, y+o.Y, srcX, srcY, srcW, srcH)
+		return
+	}
+	m := me.matrix()
+	x1, y1 := float64(x), float64(y)
+	x2, y2 := x1+float64(t.Width), y1+float64(t.Height)
+	p.DrawImageQuad(t, srcX, srcY, srcW, srcH, [4]p.PointF{
+		m.apply(p.PointF{X: x1, Y: y1}),
+		m.apply(p.PointF{X: x2, Y: y1}),
+		m.apply(p.PointF{X: x2, Y: y2}),
+		m.apply(p.PointF{X: x1, Y: y2}),
+	})
 }
 
 //Draws the text at the given coordinates.
 func (me *Canvas) DrawText(text *Text, x, y int) {
-	p.DrawImage(text.text, x, y, 0, 0, text.Width(), text.Height())
+	me.drawTexture(text.text, x, y, 0, 0, text.Width(), text.Height())
 }
 
 //Draws the image at the given coordinates.
@@ -116,10 +565,41 @@ func (me *Canvas) DrawAnimation(animation *Animation, x, y int) {
 
 //Draws the image at the given coordinates.
 func (me *Canvas) DrawImage(img *Image, x, y int) {
-	p.DrawImage(img.img, x, y, img.clipX(), img.clipY(), img.clipW(), img.clipH())
+	me.drawTexture(img.img, x, y, img.clipX(), img.clipY(), img.clipW(), img.clipH())
+}
+
+//Draws the image at the given coordinates, faded and tinted by the options.
+//The image's texture is left as it was, so other Images loaded from the same file are not affected.
+func (me *Canvas) DrawImageOpts(img *Image, x, y int, opts ImageOptions) {
+	mod := p.Color{Red: 255, Green: 255, Blue: 255, Alpha: 255 - opts.Fade}
+	if opts.Tint != nil {
+		mod.Red, mod.Green, mod.Blue = opts.Tint.Red, opts.Tint.Green, opts.Tint.Blue
+	}
+	p.SetImageColor(mod)
+	me.DrawImage(img, x, y)
+	p.SetImageColor(p.Color{Red: 255, Green: 255, Blue: 255, Alpha: 255})
+}
+
+//Draws the src rect of the image stretched to the dest rect on this Canvas, mirrored by flip,
+//then rotated by angle degrees clockwise around the pivot, which is relative to the dest rect's top left corner.
+//A src with no size is the image's clip rect.
+func (me *Canvas) DrawImageEx(img *Image, src, dest starfish.Bounds, flip int, angle float64, pivot starfish.Point) {
+	if src.Width == 0 || src.Height == 0 {
+		src = starfish.Bounds{starfish.Point{img.clipX(), img.clipY()}, starfish.Size{img.clipW(), img.clipH()}}
+	}
+	if o, ok := me.offset(); ok {
+		p.DrawImageEx(img.img, src.X, src.Y, src.Width, src.Height, dest.X+o.X, dest.Y+o.Y, dest.Width, dest.Height, flip, angle, pivot.X, pivot.Y)
+		return
+	}
+	m := me.matrix()
+	quad := p.ImageExQuad(dest.X, dest.Y, dest.Width, dest.Height, flip, angle, pivot.X, pivot.Y)
+	for i, pt := range quad {
+		quad[i] = m.apply(pt)
+	}
+	p.DrawImageQuad(img.img, src.X, src.Y, src.Width, src.Height, quad)
 }
 
 //Draws the image at the given coordinates.
 func (me *Canvas) DrawImageCrop(img *Image, x, y int, srcBnds starfish.Bounds) {
-	p.DrawImage(img.img, x, y, srcBnds.X, srcBnds.Y, srcBnds.Width, srcBnds.Height)
+	me.drawTexture(img.img, x, y, srcBnds.X, srcBnds.Y, srcBnds.Width, srcBnds.Height)
 }
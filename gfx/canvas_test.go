@@ -0,0 +1,377 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	starfish "../"
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"image/color"
+	"testing"
+)
+
+//Draws with a new Canvas on a new headless display of the given size, and returns the frame.
+func drawFrame(width, height int, draw func(c *Canvas)) *image.RGBA {
+	h := p.NewHeadless()
+	p.SetBackend(h)
+	p.OpenDisplay(width, height, false)
+	h.Draw(func() {
+		c := newCanvas()
+		c.load()
+		draw(&c)
+	})
+	return h.Frame()
+}
+
+//Returns a white 3x2 Target, red at its top left, green at its top right and blue at its bottom left.
+func newCornerTarget(t *testing.T) *Target {
+	img, err := NewTarget(3, 2)
+	if err != nil {
+		t.Fatal("NewTarget failed:", err)
+	}
+	img.DrawFunc(func(c *Canvas) {
+		c.SetRGB(255, 255, 255)
+		c.FillRect(0, 0, 3, 2)
+		c.SetRGB(255, 0, 0)
+		c.FillRect(0, 0, 1, 1)
+		c.SetRGB(0, 255, 0)
+		c.FillRect(2, 0, 1, 1)
+		c.SetRGB(0, 0, 255)
+		c.FillRect(0, 1, 1, 1)
+	})
+	return img
+}
+
+//Returns how many pixels of the frame in the given rect anything was drawn on.
+func countDrawn(f *image.RGBA, r image.Rectangle) (n int) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if f.RGBAAt(x, y).A != 0 {
+				n++
+			}
+		}
+	}
+	return
+}
+
+func TestCanvasDrawLine(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	f := drawFrame(32, 32, func(c *Canvas) {
+		c.SetRGB(255, 255, 255)
+		c.DrawLine(2, 2, 12, 7)
+		c.SetLineWidth(3)
+		c.DrawLine(2, 20, 20, 20)
+		c.SetLineWidth(1)
+		c.PushViewport(24, 0, 4, 32)
+		c.DrawLine(0, 4, 30, 4)
+		c.PopViewport()
+	})
+	if f.RGBAAt(2, 2) != white || f.RGBAAt(12, 7) != white {
+		t.Error("DrawLine does not draw both of its ends.")
+	}
+	for x := 2; x <= 12; x++ {
+		if n := countDrawn(f, image.Rect(x, 0, x+1, 10)); n != 1 {
+			t.Errorf("A 1 pixel line covers %d pixels of column %d, expected 1.", n, x)
+		}
+	}
+	for x := 2; x <= 20; x++ {
+		if f.RGBAAt(x, 19) != white || f.RGBAAt(x, 20) != white || f.RGBAAt(x, 21) != white {
+			t.Fatalf("A 3 pixel line does not cover column %d from 19 to 21.", x)
+		}
+		if f.RGBAAt(x, 18).A != 0 || f.RGBAAt(x, 22).A != 0 {
+			t.Fatalf("A 3 pixel line is wider than 3 pixels at column %d.", x)
+		}
+	}
+	if n := countDrawn(f, image.Rect(22, 4, 32, 5)); n != 4 || f.RGBAAt(24, 4) != white || f.RGBAAt(27, 4) != white {
+		t.Errorf("A line in a viewport covers %d pixels of its row, expected the viewport's 4.", n)
+	}
+}
+
+func TestCanvasDrawLines(t *testing.T) {
+	f := drawFrame(32, 32, func(c *Canvas) {
+		c.SetRGB(255, 0, 0)
+		c.DrawLines([]starfish.Point{{2, 4}, {10, 4}, {10, 12}, {20, 12}})
+		//turned a quarter clockwise, the line runs down the display, centered on column 19
+		c.Translate(20, 18)
+		c.Rotate(90)
+		c.SetLineWidth(3)
+		c.DrawLine(0, 0, 10, 0)
+	})
+	if n := countDrawn(f, image.Rect(0, 0, 32, 16)); n != 9+8+10 {
+		t.Errorf("DrawLines covers %d pixels, expected the 27 of its lines.", n)
+	}
+	for _, pt := range []image.Point{{2, 4}, {10, 4}, {10, 12}, {20, 12}} {
+		if f.RGBAAt(pt.X, pt.Y).A == 0 {
+			t.Errorf("DrawLines does not draw its point at %v.", pt)
+		}
+	}
+	if n := countDrawn(f, image.Rect(0, 16, 32, 32)); n != 3*11 {
+		t.Errorf("A rotated 3 pixel line covers %d pixels, expected 33 like an unrotated one.", n)
+	}
+	if n := countDrawn(f, image.Rect(18, 18, 21, 29)); n != 3*11 {
+		t.Errorf("A rotated 3 pixel line covers %d of the pixels of columns 18 to 20, rows 18 to 28.", n)
+	}
+}
+
+func TestCanvasCircles(t *testing.T) {
+	f := drawFrame(64, 48, func(c *Canvas) {
+		c.SetRGB(255, 0, 0)
+		c.FillCircle(10, 10, 6)
+		c.DrawEllipse(32, 10, 8, 4)
+		c.FillPie(10, 34, 8, 0, 90)
+		c.DrawArc(32, 34, 8, 180, 270)
+		c.SetAntialias(true)
+		c.FillCircle(52, 10, 6)
+		c.Translate(52, 34)
+		c.Scale(2, 1)
+		c.SetAntialias(false)
+		c.FillCircle(0, 0, 4)
+	})
+	//a filled circle reaches exactly its radius from its center pixel
+	for _, pt := range []image.Point{{4, 10}, {16, 10}, {10, 4}, {10, 16}} {
+		if f.RGBAAt(pt.X, pt.Y).A == 0 {
+			t.Errorf("FillCircle does not reach %v.", pt)
+		}
+	}
+	for _, pt := range []image.Point{{3, 10}, {17, 10}, {10, 3}, {10, 17}, {5, 5}, {15, 15}} {
+		if f.RGBAAt(pt.X, pt.Y).A != 0 {
+			t.Errorf("FillCircle reaches %v, beyond its radius.", pt)
+		}
+	}
+	if f.RGBAAt(24, 10).A == 0 || f.RGBAAt(40, 10).A == 0 || f.RGBAAt(32, 6).A == 0 || f.RGBAAt(32, 14).A == 0 {
+		t.Error("DrawEllipse does not reach its radii.")
+	}
+	if n := countDrawn(f, image.Rect(28, 8, 37, 13)); n != 0 {
+		t.Errorf("DrawEllipse draws %d pixels inside of its outline.", n)
+	}
+	//the pie goes clockwise from the right to the bottom
+	if f.RGBAAt(13, 37).A == 0 || f.RGBAAt(7, 37).A != 0 || f.RGBAAt(13, 31).A != 0 || f.RGBAAt(7, 31).A != 0 {
+		t.Error("FillPie does not fill only the quarter from 0 to 90 degrees.")
+	}
+	if f.RGBAAt(24, 34).A == 0 || f.RGBAAt(32, 26).A == 0 || f.RGBAAt(40, 34).A != 0 || f.RGBAAt(32, 42).A != 0 {
+		t.Error("DrawArc does not draw only the quarter from 180 to 270 degrees.")
+	}
+	if n := countDrawn(f, image.Rect(27, 29, 32, 34)); n != 0 {
+		t.Errorf("DrawArc draws %d pixels inside of its circle.", n)
+	}
+	//an antialiased circle is solid inside and partly covers the pixels its edge crosses
+	if c := f.RGBAAt(52, 10); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("An antialiased circle is %v at its center, expected solid red.", c)
+	}
+	partial := 0
+	for y := 2; y < 19; y++ {
+		for x := 44; x < 61; x++ {
+			if a := f.RGBAAt(x, y).A; a != 0 && a != 255 {
+				partial++
+			}
+		}
+	}
+	if partial < 8 {
+		t.Errorf("An antialiased circle has %d partly covered pixels, expected its edge to be smoothed.", partial)
+	}
+	//scaled twice as wide, a circle is twice as wide as it is tall
+	if f.RGBAAt(44, 34).A == 0 || f.RGBAAt(60, 34).A == 0 || f.RGBAAt(52, 30).A == 0 || f.RGBAAt(52, 38).A == 0 {
+		t.Error("A circle scaled twice as wide does not reach twice its radius across and its radius down.")
+	}
+	if f.RGBAAt(42, 34).A != 0 || f.RGBAAt(62, 34).A != 0 || f.RGBAAt(52, 28).A != 0 || f.RGBAAt(52, 40).A != 0 {
+		t.Error("A circle scaled twice as wide reaches beyond twice its radius across or its radius down.")
+	}
+}
+
+func TestCanvasFillPolygon(t *testing.T) {
+	star := []starfish.Point{{16, 2}, {24, 28}, {2, 12}, {30, 12}, {8, 28}}
+	f := drawFrame(96, 64, func(c *Canvas) {
+		c.SetRGBA(0, 0, 255, 128)
+		c.FillPolygon(star)
+		c.PushViewport(32, 0, 32, 32)
+		c.SetFillRule(FillRule_NonZero)
+		c.FillPolygon(star)
+		c.PopViewport()
+		c.PushViewport(64, 0, 32, 32)
+		c.SetAntialias(true)
+		c.FillPolygon(star)
+		c.PopViewport()
+		c.SetAntialias(false)
+		c.SetRGB(255, 255, 255)
+		//a U, whose notch stays empty
+		c.FillPolygon([]starfish.Point{{2, 34}, {10, 34}, {10, 50}, {20, 50}, {20, 34}, {28, 34}, {28, 60}, {2, 60}})
+		c.DrawPolygon([]starfish.Point{{34, 34}, {60, 34}, {34, 60}})
+	})
+	tip := f.RGBAAt(16, 6)
+	if tip.A < 126 || tip.A > 130 {
+		t.Fatalf("A translucent star is %v at its tip, expected half opaque blue.", tip)
+	}
+	if f.RGBAAt(16, 15).A != 0 {
+		t.Error("The middle of a star is filled by the even-odd rule.")
+	}
+	if c := f.RGBAAt(48, 15); c != tip {
+		t.Errorf("The middle of a star filled by the nonzero rule is %v, expected it to blend once like its tip, %v.", c, tip)
+	}
+	if c := f.RGBAAt(80, 15); c != tip {
+		t.Errorf("The middle of an antialiased star is %v, expected it to blend once like its tip, %v.", c, tip)
+	}
+	if c := f.RGBAAt(80, 6); c != tip {
+		t.Errorf("The tip of an antialiased star is %v, expected it to be solid inside like its tip, %v.", c, tip)
+	}
+	//edges crossing each other must not darken where they cross
+	for y := 0; y < 32; y++ {
+		for x := 64; x < 96; x++ {
+			if a := f.RGBAAt(x, y).A; a > tip.A {
+				t.Fatalf("An antialiased translucent star blends more than once at (%d, %d).", x, y)
+			}
+		}
+	}
+	if f.RGBAAt(4, 40).A == 0 || f.RGBAAt(24, 40).A == 0 || f.RGBAAt(15, 55).A == 0 {
+		t.Error("A concave polygon is not filled on both sides of its notch.")
+	}
+	if n := countDrawn(f, image.Rect(11, 34, 20, 50)); n != 0 {
+		t.Errorf("A concave polygon fills %d pixels of its notch.", n)
+	}
+	if f.RGBAAt(47, 34).A == 0 || f.RGBAAt(34, 47).A == 0 || f.RGBAAt(47, 47).A == 0 {
+		t.Error("DrawPolygon does not draw all of its sides, closing back to its first point.")
+	}
+	if n := countDrawn(f, image.Rect(37, 37, 45, 45)); n != 0 {
+		t.Errorf("DrawPolygon draws %d pixels inside of its outline.", n)
+	}
+}
+
+func TestCanvasDrawRect(t *testing.T) {
+	f := drawFrame(64, 32, func(c *Canvas) {
+		c.SetRGB(255, 255, 255)
+		c.DrawRect(2, 2, 20, 12)
+		c.SetRGB(0, 0, 255)
+		c.FillRect(3, 3, 18, 10)
+		c.SetLineWidth(3)
+		c.SetRGBA(255, 0, 0, 128)
+		c.DrawRect(26, 2, 20, 12)
+		//turned a quarter clockwise around (60, 2), so it covers columns 52 to 59
+		c.Translate(60, 2)
+		c.Rotate(90)
+		c.SetLineWidth(1)
+		c.DrawRect(0, 0, 12, 8)
+	})
+	//a 1 pixel border around a fill 1 pixel smaller covers the whole rect without overlapping it
+	if n := countDrawn(f, image.Rect(0, 0, 24, 16)); n != 20*12 {
+		t.Errorf("A border and the fill inside of it cover %d pixels, expected the 240 of the rect.", n)
+	}
+	for _, pt := range []image.Point{{2, 2}, {21, 2}, {2, 13}, {21, 13}, {10, 2}, {2, 8}} {
+		if c := f.RGBAAt(pt.X, pt.Y); c != (color.RGBA{255, 255, 255, 255}) {
+			t.Errorf("DrawRect's border is %v at %v, expected white.", c, pt)
+		}
+	}
+	want := f.RGBAAt(27, 8)
+	if want.A < 126 || want.A > 130 {
+		t.Fatalf("A translucent border is %v, expected half opaque red.", want)
+	}
+	for _, pt := range []image.Point{{26, 2}, {28, 4}, {45, 2}, {43, 4}, {26, 13}, {45, 13}} {
+		if c := f.RGBAAt(pt.X, pt.Y); c != want {
+			t.Errorf("A translucent 3 pixel border is %v at its corner %v, expected it to blend once, %v.", c, pt, want)
+		}
+	}
+	if n := countDrawn(f, image.Rect(24, 0, 48, 16)); n != 20*12-14*6 {
+		t.Errorf("A 3 pixel border covers %d pixels, expected 156.", n)
+	}
+	if n := countDrawn(f, image.Rect(48, 0, 64, 32)); n != 12*8-10*6 || countDrawn(f, image.Rect(52, 2, 60, 14)) != n {
+		t.Errorf("A rotated border covers %d pixels, expected the 36 of an unrotated one, turned.", n)
+	}
+}
+
+func TestCanvasDrawRoundedRect(t *testing.T) {
+	fill := drawFrame(32, 32, func(c *Canvas) {
+		c.SetRGB(255, 255, 255)
+		c.FillRoundedRect(2, 2, 24, 20, 6)
+	})
+	border := drawFrame(32, 32, func(c *Canvas) {
+		c.SetRGB(255, 255, 255)
+		c.SetLineWidth(2)
+		c.DrawRoundedRect(2, 2, 24, 20, 6)
+	})
+	in := func(f *image.RGBA, x, y int) bool {
+		return f.RGBAAt(x, y).A != 0
+	}
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if in(border, x, y) && !in(fill, x, y) {
+				t.Fatalf("DrawRoundedRect draws (%d, %d), outside of the FillRoundedRect of the same size.", x, y)
+			}
+			edge := !in(fill, x-1, y) || !in(fill, x+1, y) || !in(fill, x, y-1) || !in(fill, x, y+1)
+			if in(fill, x, y) && edge && !in(border, x, y) {
+				t.Fatalf("DrawRoundedRect leaves a gap at (%d, %d), on the edge of the FillRoundedRect of the same size.", x, y)
+			}
+		}
+	}
+	if in(border, 14, 12) || !in(border, 14, 3) || in(border, 14, 4) {
+		t.Error("DrawRoundedRect's border is not 2 pixels wide.")
+	}
+}
+
+func TestCanvasBlendModes(t *testing.T) {
+	tests := []struct {
+		mode int
+		want color.RGBA
+	}{
+		{BlendMode_Alpha, color.RGBA{200, 49, 24, 255}},
+		{BlendMode_Add, color.RGBA{255, 100, 50, 255}},
+		{BlendMode_Modulate, color.RGBA{157, 0, 0, 255}},
+		{BlendMode_Multiply, color.RGBA{178, 50, 25, 255}},
+		{BlendMode_Screen, color.RGBA{222, 100, 50, 255}},
+		{BlendMode_None, color.RGBA{100, 0, 0, 128}},
+	}
+	nearColor := func(a, b color.RGBA) bool {
+		d := func(x, y uint8) bool { return int(x)-int(y) < 3 && int(y)-int(x) < 3 }
+		return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B) && d(a.A, b.A)
+	}
+	for _, test := range tests {
+		f := drawFrame(24, 8, func(c *Canvas) {
+			img, err := NewTarget(4, 4)
+			if err != nil {
+				t.Fatal("NewTarget failed:", err)
+			}
+			defer img.Free()
+			img.DrawFunc(func(c *Canvas) {
+				c.SetBlendMode(BlendMode_None)
+				c.SetRGBA(200, 0, 0, 128)
+				c.FillRect(0, 0, 4, 4)
+			})
+			c.SetRGB(200, 100, 50)
+			c.FillRect(0, 0, 24, 8)
+			c.Save()
+			c.SetBlendMode(test.mode)
+			c.SetRGBA(200, 0, 0, 128)
+			c.FillRect(0, 0, 4, 4)
+			c.FillCircle(10, 2, 1)
+			c.DrawImage(&img.Image, 16, 0)
+			c.Restore()
+			c.SetRGBA(200, 0, 0, 128)
+			c.FillRect(0, 6, 4, 2)
+		})
+		if got := f.RGBAAt(1, 1); !nearColor(got, test.want) {
+			t.Errorf("Blend mode %d fills a rect with %v, expected %v.", test.mode, got, test.want)
+		}
+		if got := f.RGBAAt(10, 2); !nearColor(got, test.want) {
+			t.Errorf("Blend mode %d fills a circle with %v, expected %v.", test.mode, got, test.want)
+		}
+		if got := f.RGBAAt(17, 1); !nearColor(got, test.want) {
+			t.Errorf("Blend mode %d draws an image with %v, expected %v.", test.mode, got, test.want)
+		}
+		if got := f.RGBAAt(6, 1); got != (color.RGBA{200, 100, 50, 255}) {
+			t.Errorf("Blend mode %d changes pixels outside of what was drawn to %v.", test.mode, got)
+		}
+		if got := f.RGBAAt(1, 7); !nearColor(got, tests[0].want) {
+			t.Errorf("After Restore, blend mode %d still fills with %v, expected the alpha blend.", test.mode, got)
+		}
+	}
+}
@@ -17,6 +17,9 @@ package gfx
 
 import (
 	p "github.com/gtalent/starfish/plumbing"
+	_ "image/png"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,7 +27,12 @@ var displayTitle string
 var drawers []*canvasHolder
 var displayDead chan interface{}
 var drawInterval = 0
-var running = false
+
+//Whether the display is open, read by the goroutines that draw and tick animations until it closes.
+var running atomic.Bool
+
+//Settings for opening the display with OpenDisplayWithOptions.
+type DisplayOptions = p.DisplayOptions
 
 //An interface used to for telling the display what to draw.
 type Drawer interface {
@@ -43,6 +51,12 @@ type canvasHolder struct {
 	drawer Drawer
 }
 
+//Sets the Backend that the display and input are run on. Defaults to SDL.
+//Must be called before OpenDisplay.
+func SetBackend(b p.Backend) {
+	p.SetBackend(b)
+}
+
 //Sets the title of the window.
 func SetDisplayTitle(title string) {
 	displayTitle = title
@@ -114,40 +128,80 @@ func SetDrawInterval(ms int) {
 	drawInterval = ms * 1000000
 }
 
+//Held for the whole of each Draw, so the Drawer timings recorded are those of the frame just drawn.
+var drawLock sync.Mutex
+
 //Used to manually draw the screen.
 func Draw() {
-	p.Draw()
+	drawLock.Lock()
+	defer drawLock.Unlock()
+	start := time.Now()
+	timing := p.Draw()
+	stats.record(start, timing, drawerTimes)
 }
 
 //Opens a window.
-//Returns an indicator of success.
-func OpenDisplay(w, h int, fullscreen bool) bool {
-	if !running {
-		p.SetDrawFunc(func() {
-			for _, a := range drawers {
-				a.canvas.load()
-				a.drawer.Draw(&a.canvas)
-			}
-		})
-		p.OpenDisplay(w, h, fullscreen)
-		running = true
-		SetDrawInterval(16)
-		startAnimTick()
+//The error can be checked for ErrDisplay and ErrNoRenderer with errors.Is.
+func OpenDisplay(w, h int, fullscreen bool) error {
+	return OpenDisplayWithOptions(DisplayOptions{Width: w, Height: h, Fullscreen: fullscreen})
+}
+
+//Opens a window with the given options.
+//The error can be checked for ErrNotFound, ErrDecode, ErrDisplay, and ErrNoRenderer with errors.Is.
+//Opening the display while it is open fails with ErrDisplay and leaves it as it was; close it first
+//to open it with other options.
+func OpenDisplayWithOptions(opts DisplayOptions) error {
+	if running.Load() {
+		return &p.Error{Kind: ErrDisplay, Op: "OpenDisplay", Msg: "the display is already open"}
+	}
+	p.SetDrawFunc(func() {
+		drawerTimes = drawerTimes[:0]
+		for _, a := range drawers {
+			start := time.Now()
+			a.canvas.load()
+			a.drawer.Draw(&a.canvas)
+			a.canvas.end(a.drawer)
+			drawerTimes = append(drawerTimes, DrawerTiming{a.drawer, time.Since(start)})
+		}
+	})
+	err := p.OpenDisplayWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	running.Store(true)
+	SetDrawInterval(16)
+	startAnimTick()
+	return nil
+}
+
+//Sets the icon of the window to the given Image. The display must be open.
+func SetDisplayIcon(icon *Image) error {
+	t, err := NewTarget(icon.Width(), icon.Height())
+	if err != nil {
+		return err
+	}
+	defer t.Free()
+	t.DrawFunc(func(c *Canvas) {
+		c.DrawImage(icon, 0, 0)
+	})
+	pix, err := p.ReadTarget(t.img)
+	if err != nil {
+		return err
 	}
-	return true
+	return p.SetDisplayIcon(pix)
 }
 
 //Closes the window.
 func CloseDisplay() {
-	running = false
+	running.Store(false)
 	p.CloseDisplay()
 }
 
 //Blocks until CloseDisplay is called, regardless of whether or not OpenDisplay has been called.
 func Main() {
 	go func() {
-		for running {
-			p.Draw()
+		for running.Load() {
+			Draw()
 			time.Sleep(time.Duration(drawInterval))
 		}
 	}()
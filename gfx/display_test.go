@@ -0,0 +1,59 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	"errors"
+	p "github.com/gtalent/starfish/plumbing"
+	"testing"
+)
+
+func TestDisplayIcon(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	err := OpenDisplayWithOptions(DisplayOptions{Width: 8, Height: 8, IconPath: "testdata/missing.png"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Missing icon returned %v, expected ErrNotFound", err)
+	}
+
+	if err := OpenDisplay(8, 8, false); err != nil {
+		t.Fatal("OpenDisplay failed:", err)
+	}
+	defer CloseDisplay()
+	icon, err := LoadImage("testdata/red.png")
+	if err != nil {
+		t.Fatal("LoadImage failed:", err)
+	}
+	defer icon.Free()
+	if err := SetDisplayIcon(icon); err != nil {
+		t.Error("SetDisplayIcon failed:", err)
+	}
+}
+
+func TestOpenDisplayWhileOpen(t *testing.T) {
+	h := p.NewHeadless()
+	p.SetBackend(h)
+	if err := OpenDisplay(8, 8, false); err != nil {
+		t.Fatal("OpenDisplay failed:", err)
+	}
+	defer CloseDisplay()
+	if err := OpenDisplayWithOptions(DisplayOptions{Width: 16, Height: 16}); !errors.Is(err, ErrDisplay) {
+		t.Errorf("Opening the display while it is open returned %v, expected ErrDisplay", err)
+	}
+	Draw()
+	if b := h.Frame().Bounds(); b.Dx() != 8 || b.Dy() != 8 {
+		t.Errorf("Opening the display while it is open resized it to %v", b)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	"errors"
+	p "github.com/gtalent/starfish/plumbing"
+)
+
+//Kinds of errors returned by gfx. Check for them with errors.Is.
+var (
+	//A file to load does not exist.
+	ErrNotFound = p.ErrNotFound
+	//A file to load is not an image or font that can be read.
+	ErrDecode = p.ErrDecode
+	//There is no renderer, usually because the display has not been opened.
+	ErrNoRenderer = p.ErrNoRenderer
+	//The display could not be opened.
+	ErrDisplay = p.ErrDisplay
+	//A texture could not be created for an image.
+	ErrTexture = p.ErrTexture
+	//Text could not be rendered with a font.
+	ErrText = p.ErrText
+	//The Image of a Target cannot be resized or rotated.
+	ErrTarget = errors.New("cannot resize or rotate the Image of a Target")
+)
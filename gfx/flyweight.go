@@ -40,11 +40,11 @@ func (me stringKey) String() string {
 type flyweight struct {
 	lock     sync.Mutex
 	items    map[string]*flynode
-	loader   func(*flyweight, key) interface{}
+	loader   func(*flyweight, key) (interface{}, error)
 	unloader func(*flyweight, key, interface{})
 }
 
-func newFlyweight(loader func(*flyweight, key) interface{}, unloader func(*flyweight, key, interface{})) *flyweight {
+func newFlyweight(loader func(*flyweight, key) (interface{}, error), unloader func(*flyweight, key, interface{})) *flyweight {
 	r := new(flyweight)
 	r.loader = loader
 	r.unloader = unloader
@@ -52,13 +52,19 @@ func newFlyweight(loader func(*flyweight, key) interface{}, unloader func(*flywe
 	return r
 }
 
-func (me *flyweight) checkout(key key) interface{} {
+//Returns the value for the given key, loading it if no one else has it checked out.
+//Values that fail to load are not kept.
+func (me *flyweight) checkout(key key) (interface{}, error) {
 	for {
 		node, ok := me.items[key.String()]
 		if !ok {
+			val, err := me.loader(me, key)
+			if err != nil {
+				return nil, err
+			}
 			node = new(flynode)
 			node.key = key
-			node.val = me.loader(me, key)
+			node.val = val
 			node.clients = 1
 
 			me.lock.Lock()
@@ -72,11 +78,13 @@ func (me *flyweight) checkout(key key) interface{} {
 				}
 				n.clients++
 				n.Unlock()
-				return n.val
+				//another client loaded it first
+				me.unloader(me, key, val)
+				return n.val, nil
 			}
 			me.items[key.String()] = node
 			me.lock.Unlock()
-			return node.val
+			return node.val, nil
 		} else {
 			node.Lock()
 			if node.clients == 0 {
@@ -86,16 +94,14 @@ func (me *flyweight) checkout(key key) interface{} {
 			}
 			node.clients++
 			node.Unlock()
-			return node.val
+			return node.val, nil
 		}
 	}
-	return nil
 }
 
 func (me *flyweight) checkin(key key) {
 	n := me.items[key.String()]
 	if n == nil {
-		me.lock.Unlock()
 		return
 	}
 	n.Lock()
@@ -29,14 +29,14 @@ func TestResourceManager(t *testing.T) {
 	outKey := ""
 	inKey := ""
 	inVal := 0
-	rsrcs := newFlyweight(func(me *flyweight, key key) interface{} {
+	rsrcs := newFlyweight(func(me *flyweight, key key) (interface{}, error) {
 		outKey = key.String()
-		return 42
+		return 42, nil
 	}, func(me *flyweight, key key, val interface{}) {
 		inKey = key.String()
 		inVal = val.(int)
 	})
-	v := rsrcs.checkout(stringKey("Narf!"))
+	v, _ := rsrcs.checkout(stringKey("Narf!"))
 	rsrcs.checkin(stringKey("Narf!"))
 	if v != 42 {
 		t.Error("flyweight.checkout does not return the right value.")
@@ -0,0 +1,149 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+//Package gfxtest provides helpers for testing gfx Drawers against golden images.
+//Frames are drawn with the headless backend, so no display is needed.
+//Run tests with -gfxtest.update to regenerate the golden images.
+package gfxtest
+
+import (
+	"flag"
+	"fmt"
+	"github.com/gtalent/starfish/gfx"
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var update = flag.Bool("gfxtest.update", false, "if true, Golden writes the rendered frames as the new golden images")
+
+var renderLock sync.Mutex
+
+//Draws one frame of the given Drawer on a headless display of the given size.
+//This replaces the current Backend and closes the display after, and any Drawers already added are
+//drawn beneath d.
+func Render(d gfx.Drawer, width, height int) *image.RGBA {
+	renderLock.Lock()
+	defer renderLock.Unlock()
+	h := p.NewHeadless()
+	gfx.SetBackend(h)
+	h.OpenDisplay(p.DisplayOptions{Width: width, Height: height})
+	gfx.OpenDisplay(width, height, false)
+	defer gfx.CloseDisplay()
+	//wrap d so that it can be removed even if it is not comparable
+	ref := &drawer{d}
+	gfx.AddDrawer(ref)
+	gfx.Draw()
+	gfx.RemoveDrawer(ref)
+	return h.Frame()
+}
+
+type drawer struct {
+	gfx.Drawer
+}
+
+//Compares the two images, allowing each channel of each pixel to differ by up to tolerance.
+//Returns the number of pixels that differ by more than that and an image marking them in red.
+//The images are compared as non-premultiplied colors.
+func Compare(got, want image.Image, tolerance uint8) (int, *image.NRGBA) {
+	bnds := want.Bounds()
+	diff := image.NewNRGBA(image.Rect(0, 0, bnds.Dx(), bnds.Dy()))
+	if got.Bounds().Size() != bnds.Size() {
+		return bnds.Dx() * bnds.Dy(), diff
+	}
+	off := got.Bounds().Min.Sub(bnds.Min)
+	bad := 0
+	for y := bnds.Min.Y; y < bnds.Max.Y; y++ {
+		for x := bnds.Min.X; x < bnds.Max.X; x++ {
+			g := color.NRGBAModel.Convert(got.At(x+off.X, y+off.Y)).(color.NRGBA)
+			w := color.NRGBAModel.Convert(want.At(x, y)).(color.NRGBA)
+			dx, dy := x-bnds.Min.X, y-bnds.Min.Y
+			if channelDiff(g.R, w.R) > tolerance || channelDiff(g.G, w.G) > tolerance ||
+				channelDiff(g.B, w.B) > tolerance || channelDiff(g.A, w.A) > tolerance {
+				bad++
+				diff.SetNRGBA(dx, dy, color.NRGBA{255, 0, 0, 255})
+			} else {
+				//faded gray copy of the expected pixel for context
+				gray := uint8((uint16(w.R) + uint16(w.G) + uint16(w.B)) / 3 * uint16(w.A) / 255)
+				diff.SetNRGBA(dx, dy, color.NRGBA{gray, gray, gray, 64})
+			}
+		}
+	}
+	return bad, diff
+}
+
+//Draws one frame of d at the given size and compares it to the PNG at golden, allowing each
+//channel to differ by up to tolerance.
+//On a mismatch the test fails, and the frame and a diff image are written next to the golden
+//image with _got.png and _diff.png suffixes.
+//If -gfxtest.update is set, the frame is written as the golden image instead.
+func Golden(t testing.TB, d gfx.Drawer, width, height int, golden string, tolerance uint8) {
+	t.Helper()
+	got := Render(d, width, height)
+	if *update {
+		if err := writePNG(golden, got); err != nil {
+			t.Fatal("gfxtest: could not update golden image:", err)
+		}
+		return
+	}
+	want, err := readPNG(golden)
+	if err != nil {
+		t.Fatal("gfxtest: could not read golden image (run with -gfxtest.update to create it):", err)
+	}
+	if got.Bounds().Size() != want.Bounds().Size() {
+		t.Errorf("gfxtest: %s: frame is %v, golden image is %v", golden, got.Bounds().Size(), want.Bounds().Size())
+		return
+	}
+	if bad, diff := Compare(got, want, tolerance); bad != 0 {
+		base := strings.TrimSuffix(golden, ".png")
+		writePNG(base+"_got.png", got)
+		writePNG(base+"_diff.png", diff)
+		t.Errorf("gfxtest: %s: %d pixels differ by more than %d, see %s", golden, bad, tolerance, base+"_diff.png")
+	}
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding %s: %v", path, err)
+	}
+	return f.Close()
+}
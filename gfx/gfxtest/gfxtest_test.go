@@ -0,0 +1,64 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfxtest
+
+import (
+	"github.com/gtalent/starfish/gfx"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCompareTolerance(t *testing.T) {
+	want := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	want.Set(0, 0, color.RGBA{100, 100, 100, 255})
+	want.Set(1, 0, color.RGBA{100, 100, 100, 255})
+	got := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	got.Set(0, 0, color.RGBA{102, 100, 100, 255})
+	got.Set(1, 0, color.RGBA{110, 100, 100, 255})
+
+	if bad, _ := Compare(got, want, 2); bad != 1 {
+		t.Errorf("Compare found %d bad pixels, should be 1.", bad)
+	}
+	bad, diff := Compare(got, want, 0)
+	if bad != 2 {
+		t.Errorf("Compare found %d bad pixels, should be 2.", bad)
+	}
+	if diff.NRGBAAt(1, 0) != (color.NRGBA{255, 0, 0, 255}) {
+		t.Error("Compare does not mark bad pixels in its diff image.")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, gfxDrawFunc(func(c *gfx.Canvas) {
+		c.SetRGB(0, 0, 0)
+		c.FillRect(0, 0, 64, 48)
+		c.SetRGBA(0, 0, 255, 255)
+		c.FillRect(4, 4, 20, 20)
+		c.PushViewport(30, 10, 20, 20)
+		{
+			c.SetRGBA(0, 255, 100, 127)
+			c.FillRect(-5, -5, 20, 20)
+		}
+		c.PopViewport()
+	}), 64, 48, "testdata/viewport.png", 0)
+}
+
+type gfxDrawFunc func(*gfx.Canvas)
+
+func (me gfxDrawFunc) Draw(c *gfx.Canvas) {
+	me(c)
+}
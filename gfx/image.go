@@ -31,6 +31,7 @@ type imageKey struct {
 	Angle  float64
 	Width  int
 	Height int
+	Filter int
 }
 
 func (me *imageKey) String() string {
@@ -39,15 +40,28 @@ func (me *imageKey) String() string {
 }
 
 var images = newFlyweight(
-	func(me *flyweight, path key) interface{} {
+	func(me *flyweight, path key) (interface{}, error) {
 		key := path.(*imageKey)
 		var i *p.Image
-		var k imageKey
+		//lets go of the source once a new image has been made from it
+		var release func()
 		if key.Label.FilePath {
-			i = p.LoadImage(key.Label.Str)
+			var err error
+			if i, err = p.LoadImage(key.Label.Str); err != nil {
+				return nil, err
+			}
+			release = func() { p.FreeImage(i) }
 		} else {
-			json.Unmarshal([]byte(key.Label.Str), &k)
-			i = me.checkout(&k).(*p.Image)
+			var k imageKey
+			if err := json.Unmarshal([]byte(key.Label.Str), &k); err != nil {
+				return nil, err
+			}
+			v, err := me.checkout(&k)
+			if err != nil {
+				return nil, err
+			}
+			i = v.(*p.Image)
+			release = func() { me.checkin(&k) }
 		}
 		var w, h int
 		if key.Width == -1 {
@@ -60,10 +74,12 @@ var images = newFlyweight(
 		} else {
 			h = key.Height
 		}
-		if (i != nil) && (w != int(i.W()) || h != int(i.H()) || key.Angle != 0) {
-			i = p.ResizeAngleOf(i, key.Angle, w, h)
+		//images made from other images always get their own texture, so each can be freed on its own
+		if !key.Label.FilePath || w != int(i.W()) || h != int(i.H()) || key.Angle != 0 {
+			defer release()
+			return p.ResizeAngleOf(i, key.Angle, w, h, key.Filter)
 		}
-		return i
+		return i, nil
 	},
 	func(me *flyweight, path key, img interface{}) {
 		i := img.(*p.Image)
@@ -75,38 +91,84 @@ type Image struct {
 	key     imageKey
 	size    starfish.Size
 	srcBnds starfish.Bounds
+	target  bool //not managed by the images flyweight
 }
 
-//Loads the image at the given path, or nil if the image was not found.
-func LoadImage(path string) *Image {
+//Ways to mirror an Image drawn with Canvas.DrawImageEx, which can be combined with |.
+const (
+	Flip_None int = p.Flip_None
+	//Mirrors the image left to right.
+	Flip_Horizontal = p.Flip_Horizontal
+	//Mirrors the image top to bottom.
+	Flip_Vertical = p.Flip_Vertical
+)
+
+//How source pixels are blended when images are loaded or made at a new size or angle.
+const (
+	//Takes the source pixel nearest each new pixel, keeping hard edges, which suits pixel art.
+	Filter_Nearest int = p.Filter_Nearest
+	//Blends the 2x2 source pixels around each new pixel.
+	Filter_Bilinear = p.Filter_Bilinear
+	//Blends the 4x4 source pixels around each new pixel, which keeps scaled up images sharper than bilinear.
+	Filter_Bicubic = p.Filter_Bicubic
+)
+
+var imageFilter = Filter_Bilinear
+
+//Sets the filter images loaded or made at a new size or angle from now on are resampled with.
+//Defaults to Filter_Bilinear.
+func SetImageFilter(filter int) {
+	imageFilter = filter
+}
+
+//How to fade and tint an Image drawn with Canvas.DrawImageOpts.
+//The zero value draws the image as it is.
+type ImageOptions struct {
+	//How much to fade the image, from 0 for as opaque as the image itself to 255 for invisible.
+	Fade byte
+	//The color the image's colors are multiplied by, or nil to leave them as they are. Its alpha is not used.
+	Tint *Color
+}
+
+//Loads the image at the given path.
+//The error can be checked for ErrNotFound, ErrDecode, and the like with errors.Is.
+func LoadImage(path string) (*Image, error) {
 	return LoadImageSize(path, -1, -1)
 }
 
-//Loads the image at the given path at the given angle, or nil if the image was not found.
-func LoadImageAngle(path string, angle float64) *Image {
+//Loads the image at the given path at the given angle.
+func LoadImageAngle(path string, angle float64) (*Image, error) {
 	return LoadImageSizeAngle(path, -1, -1, angle)
 }
 
-//Loads the image at the given path at the given size, or nil if the image was not found.
-func LoadImageSize(path string, width, height int) *Image {
+//Loads the image at the given path at the given size.
+func LoadImageSize(path string, width, height int) (*Image, error) {
 	return LoadImageSizeAngle(path, width, height, 0)
 }
 
-//Loads the image at the given path at the given angle and at the given size, or nil if the image was not found.
-func LoadImageSizeAngle(path string, w, h int, angle float64) (img *Image) {
+//Loads the image at the given path at the given angle and at the given size.
+//It is stretched to the size, then rotated by the angle in degrees clockwise, resampled with the filter
+//set by SetImageFilter. Rotated images are the size of their bounding box.
+func LoadImageSizeAngle(path string, w, h int, angle float64) (*Image, error) {
 	var key imageKey
 	key.Label.FilePath = true
 	key.Label.Str = path
 	key.Angle = angle
 	key.Width = w
 	key.Height = h
-	i := images.checkout(&key).(*p.Image)
-	img = new(Image)
-	img.img = i
+	if w != -1 || h != -1 || angle != 0 {
+		key.Filter = imageFilter
+	}
+	i, err := images.checkout(&key)
+	if err != nil {
+		return nil, err
+	}
+	img := new(Image)
+	img.img = i.(*p.Image)
 	img.key = key
 	img.ResetClipRect()
 	img.ResetSize()
-	return
+	return img, nil
 }
 
 func (me *Image) SetSize(w, h int) {
@@ -186,34 +248,54 @@ func (me *Image) Path() string {
 	return me.key.Label.Str
 }
 
-//Returns a version of this Image at the given angle and given size.
-func (me *Image) ReSizeAngleOf(w, h int, angle float64) *Image {
+//Returns a version of this Image at the given angle and given size, resampled with the filter set by SetImageFilter.
+//The angle is in degrees clockwise, and the new Image is the size of the rotated image's bounding box.
+//It is always made from the image as it was loaded, so angles and sizes do not add up and
+//resampling does not blur it further each time. It has its own texture and must be freed on its own.
+//Returns ErrTarget for the Images of Targets.
+func (me *Image) ReSizeAngleOf(w, h int, angle float64) (*Image, error) {
+	if me.target {
+		return nil, ErrTarget
+	}
 	var key imageKey
-	key.Label.FilePath = false
-	key.Label.Str = me.key.String()
+	key.Label = me.key.Label
+	if key.Label.FilePath {
+		src := imageKey{Label: me.key.Label, Width: -1, Height: -1}
+		key.Label = imageLabel{Str: src.String()}
+	}
 	key.Angle = angle
 	key.Width = w
 	key.Height = h
-	i := images.checkout(&key).(*p.Image)
+	key.Filter = imageFilter
+	i, err := images.checkout(&key)
+	if err != nil {
+		return nil, err
+	}
 	img := new(Image)
-	img.img = i
+	img.img = i.(*p.Image)
 	img.key = key
-	return img
+	img.ResetClipRect()
+	img.ResetSize()
+	return img, nil
 }
 
 //Returns a version of this Image at the given angle.
-func (me *Image) ReangleOf(angle float64) *Image {
+func (me *Image) ReangleOf(angle float64) (*Image, error) {
 	return me.ReSizeAngleOf(me.key.Width, me.key.Height, angle)
 }
 
 //Returns a version of this Image at the given size.
-func (me *Image) ResizeOf(w, h int) *Image {
+func (me *Image) ResizeOf(w, h int) (*Image, error) {
 	return me.ReSizeAngleOf(w, h, me.key.Angle)
 }
 
 //Nils this image and lets the resource manager know this object is no longer using the image data.
 func (me *Image) Free() {
-	images.checkin(&me.key)
+	if me.target {
+		p.FreeImage(me.img)
+	} else {
+		images.checkin(&me.key)
+	}
 	me.img = nil
 	me.key.Label.Str = ""
 }
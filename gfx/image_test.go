@@ -0,0 +1,252 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	starfish "../"
+	"errors"
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadImageErrors(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(8, 8, false)
+
+	if _, err := LoadImage("testdata/missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Missing image returned %v, expected ErrNotFound", err)
+	}
+	if _, err := LoadImage("testdata/notanimage.png"); !errors.Is(err, ErrDecode) {
+		t.Errorf("Undecodable image returned %v, expected ErrDecode", err)
+	}
+	img, err := LoadImageSize("testdata/red.png", 2, 2)
+	if err != nil {
+		t.Fatal("LoadImageSize failed:", err)
+	}
+	img.Free()
+}
+
+func TestTargetResizeError(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(8, 8, false)
+
+	target, err := NewTarget(4, 4)
+	if err != nil {
+		t.Fatal("NewTarget failed:", err)
+	}
+	defer target.Free()
+	if _, err := target.ResizeOf(2, 2); err != ErrTarget {
+		t.Errorf("Resizing a Target returned %v, expected ErrTarget", err)
+	}
+}
+
+func TestImageResizeOf(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(8, 8, false)
+
+	img, err := LoadImage("testdata/red.png")
+	if err != nil {
+		t.Fatal("LoadImage failed:", err)
+	}
+	w, h := img.Width(), img.Height()
+	wide, err := img.ResizeOf(w*2, h)
+	if err != nil {
+		t.Fatal("ResizeOf failed:", err)
+	}
+	defer wide.Free()
+	turned, err := wide.ReangleOf(90)
+	if err != nil {
+		t.Fatal("ReangleOf failed:", err)
+	}
+	defer turned.Free()
+	img.Free()
+	if wide.img.Texture == nil || wide.DefaultWidth() != w*2 || wide.Width() != w*2 || wide.DefaultHeight() != h {
+		t.Errorf("ResizeOf made a %dx%d image that depends on its source, expected %dx%d.", wide.DefaultWidth(), wide.DefaultHeight(), w*2, h)
+	}
+	if turned.DefaultWidth() != h || turned.DefaultHeight() != w*2 {
+		t.Errorf("ReangleOf(90) of a %dx%d image made it %dx%d.", w*2, h, turned.DefaultWidth(), turned.DefaultHeight())
+	}
+	back, err := turned.ReangleOf(0)
+	if err != nil {
+		t.Fatal("ReangleOf failed:", err)
+	}
+	defer back.Free()
+	if back.DefaultWidth() != w*2 || back.DefaultHeight() != h {
+		t.Errorf("ReangleOf(0) of a rotated %dx%d image made it %dx%d, angles should not add up.", w*2, h, back.DefaultWidth(), back.DefaultHeight())
+	}
+}
+
+func TestDrawImageOpts(t *testing.T) {
+	h := p.NewHeadless()
+	p.SetBackend(h)
+	p.OpenDisplay(40, 10, false)
+
+	tile, err := NewTarget(4, 4)
+	if err != nil {
+		t.Fatal("NewTarget failed:", err)
+	}
+	defer tile.Free()
+	tile.DrawFunc(func(c *Canvas) {
+		c.SetRGB(200, 100, 50)
+		c.FillRect(0, 0, 4, 4)
+	})
+	h.Draw(func() {
+		c := newCanvas()
+		c.load()
+		c.DrawImageOpts(&tile.Image, 0, 0, ImageOptions{})
+		c.DrawImageOpts(&tile.Image, 10, 0, ImageOptions{Fade: 255})
+		c.DrawImageOpts(&tile.Image, 20, 0, ImageOptions{Fade: 153})
+		c.DrawImageOpts(&tile.Image, 30, 0, ImageOptions{Tint: &Color{255, 0, 128, 0}})
+		c.DrawImage(&tile.Image, 36, 0)
+	})
+
+	f := h.Frame()
+	if c := f.RGBAAt(1, 1); c != (color.RGBA{200, 100, 50, 255}) {
+		t.Errorf("Zero ImageOptions drew %v, expected the image as it is", c)
+	}
+	if c := f.RGBAAt(11, 1); c.A != 0 {
+		t.Errorf("A Fade of 255 drew %v, expected nothing", c)
+	}
+	if c := f.RGBAAt(21, 1); c.A < 100 || c.A > 104 || c.R < 78 || c.R > 82 {
+		t.Errorf("A Fade of 153 drew %v, expected the image at 40%% alpha", c)
+	}
+	if c := f.RGBAAt(31, 1); c.R != 200 || c.G != 0 || c.B < 24 || c.B > 26 || c.A != 255 {
+		t.Errorf("A Tint drew %v, expected the image's colors multiplied by it, ignoring its alpha", c)
+	}
+	if c := f.RGBAAt(37, 1); c != (color.RGBA{200, 100, 50, 255}) {
+		t.Errorf("The image drawn after DrawImageOpts is %v, expected its options not to stick", c)
+	}
+}
+
+func TestDrawImageEx(t *testing.T) {
+	red, green, blue, white := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}, color.RGBA{255, 255, 255, 255}
+	bounds := func(x, y, w, h int) starfish.Bounds {
+		return starfish.Bounds{starfish.Point{x, y}, starfish.Size{w, h}}
+	}
+	f := drawFrame(64, 32, func(c *Canvas) {
+		img := newCornerTarget(t)
+		defer img.Free()
+		whole := starfish.Bounds{}
+		c.DrawImageEx(&img.Image, whole, bounds(2, 2, 3, 2), Flip_Horizontal, 0, starfish.Point{})
+		c.DrawImageEx(&img.Image, whole, bounds(8, 2, 3, 2), Flip_Vertical, 0, starfish.Point{})
+		c.DrawImageEx(&img.Image, whole, bounds(14, 2, 3, 2), Flip_Horizontal|Flip_Vertical, 0, starfish.Point{})
+		c.DrawImageEx(&img.Image, whole, bounds(20, 2, 6, 4), Flip_None, 0, starfish.Point{})
+		c.DrawImageEx(&img.Image, bounds(2, 0, 1, 2), bounds(30, 2, 1, 2), Flip_None, 0, starfish.Point{})
+		//turned a quarter clockwise around its top left corner, the top of the image runs down its left
+		c.DrawImageEx(&img.Image, whole, bounds(40, 2, 3, 2), Flip_None, 90, starfish.Point{})
+		//turned half around (2, 1), a flipped image is flipped the other way, a pixel further right
+		c.DrawImageEx(&img.Image, whole, bounds(50, 2, 3, 2), Flip_Horizontal, 180, starfish.Point{2, 1})
+		c.Translate(2, 20)
+		c.Scale(2, 2)
+		c.DrawImageEx(&img.Image, whole, bounds(0, 0, 3, 2), Flip_Horizontal, 0, starfish.Point{})
+	})
+	tests := []struct {
+		x, y int
+		want color.RGBA
+		what string
+	}{
+		{4, 2, red, "horizontally flipped image's top right"},
+		{2, 2, green, "horizontally flipped image's top left"},
+		{4, 3, blue, "horizontally flipped image's bottom right"},
+		{8, 3, red, "vertically flipped image's bottom left"},
+		{10, 3, green, "vertically flipped image's bottom right"},
+		{8, 2, blue, "vertically flipped image's top left"},
+		{16, 3, red, "image flipped both ways' bottom right"},
+		{14, 3, green, "image flipped both ways' bottom left"},
+		{16, 2, blue, "image flipped both ways' top right"},
+		{21, 3, red, "image stretched to twice its size's top left"},
+		{24, 3, green, "image stretched to twice its size's top right"},
+		{21, 5, blue, "image stretched to twice its size's bottom left"},
+		{30, 2, green, "image's source rect's top"},
+		{30, 3, white, "image's source rect's bottom"},
+		{39, 2, red, "image rotated around its corner's top left"},
+		{39, 4, green, "image rotated around its corner's top right"},
+		{38, 2, blue, "image rotated around its corner's bottom left"},
+		{51, 3, red, "flipped image turned half around's bottom left"},
+		{53, 3, green, "flipped image turned half around's bottom right"},
+		{51, 2, blue, "flipped image turned half around's top left"},
+		{7, 20, red, "transformed and flipped image's top right"},
+		{2, 21, green, "transformed and flipped image's top left"},
+		{6, 23, blue, "transformed and flipped image's bottom right"},
+	}
+	for _, test := range tests {
+		if c := f.RGBAAt(test.x, test.y); c != test.want {
+			t.Errorf("The %s is %v at (%d, %d), expected %v.", test.what, c, test.x, test.y, test.want)
+		}
+	}
+	if n := countDrawn(f, image.Rect(0, 0, 64, 16)); n != 6*3+24+2+6+6 {
+		t.Errorf("The images cover %d pixels, expected 56 for their dest rects.", n)
+	}
+}
+
+func TestImageResizeFilters(t *testing.T) {
+	//a 2x1 image, red on the left and blue on the right
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	src.SetRGBA(1, 0, color.RGBA{0, 0, 255, 255})
+	path := filepath.Join(t.TempDir(), "redblue.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, src); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer SetImageFilter(Filter_Bilinear)
+
+	f := drawFrame(8, 4, func(c *Canvas) {
+		img, err := LoadImage(path)
+		if err != nil {
+			t.Fatal("LoadImage failed:", err)
+		}
+		defer img.Free()
+		for i, filter := range []int{Filter_Nearest, Filter_Bilinear} {
+			SetImageFilter(filter)
+			wide, err := img.ResizeOf(4, 1)
+			if err != nil {
+				t.Fatal("ResizeOf failed:", err)
+			}
+			c.DrawImage(wide, 0, i*2)
+			wide.Free()
+		}
+		SetImageFilter(Filter_Nearest)
+		turned, err := img.ReangleOf(90)
+		if err != nil {
+			t.Fatal("ReangleOf failed:", err)
+		}
+		c.DrawImage(turned, 6, 0)
+		turned.Free()
+	})
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	if f.RGBAAt(0, 0) != red || f.RGBAAt(1, 0) != red || f.RGBAAt(2, 0) != blue || f.RGBAAt(3, 0) != blue {
+		t.Error("An image resized with Filter_Nearest does not repeat its pixels.")
+	}
+	if f.RGBAAt(0, 2) != red || f.RGBAAt(3, 2) != blue {
+		t.Error("An image resized with Filter_Bilinear changes the colors at its edges.")
+	}
+	if c := f.RGBAAt(1, 2); c.R <= c.B || c.B == 0 || c.A != 255 {
+		t.Errorf("An image resized with Filter_Bilinear is %v next to its left edge, expected mostly red blended with blue.", c)
+	}
+	if f.RGBAAt(6, 0) != red || f.RGBAAt(6, 1) != blue || f.RGBAAt(7, 0).A != 0 {
+		t.Error("An image turned a quarter clockwise does not run down from its left to its right.")
+	}
+}
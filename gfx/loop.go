@@ -0,0 +1,157 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"sync"
+	"time"
+)
+
+//An interface used for advancing game state at a fixed rate, separately from drawing.
+type Updater interface {
+	//Advances the state by dt, which is always the UpdateInterval of the loop.
+	Update(dt time.Duration)
+}
+
+type updateFunc func(time.Duration)
+
+func (me *updateFunc) Update(dt time.Duration) {
+	(*me)(dt)
+}
+
+//Settings for the loop run by Run.
+type LoopOptions struct {
+	//The fixed amount of time each update advances by. Defaults to 1/60 of a second.
+	UpdateInterval time.Duration
+	//The time between the starts of draws. Defaults to the interval set with SetDrawInterval.
+	DrawInterval time.Duration
+	//The most updates run to catch up before each draw. Defaults to 5.
+	//Time that still has not been caught up after that many updates is dropped,
+	//so that slow updates cannot keep the loop from ever drawing again.
+	MaxUpdates int
+	//The most time between draws that is caught up on, such as after the process was paused.
+	//Defaults to a quarter of a second.
+	MaxFrameTime time.Duration
+}
+
+var updatersLock sync.Mutex
+var updaters []Updater
+
+//How far between the last update and the next the current draw is, from 0 to 1.
+var interpolation float64
+
+//Adds an Updater to be advanced by the loop.
+func AddUpdater(updater Updater) {
+	updatersLock.Lock()
+	updaters = append(updaters, updater)
+	updatersLock.Unlock()
+}
+
+//Removes the given Updater.
+func RemoveUpdater(updater Updater) {
+	updatersLock.Lock()
+	defer updatersLock.Unlock()
+	for n, u := range updaters {
+		if u == updater {
+			updaters = append(updaters[:n], updaters[n+1:]...)
+			break
+		}
+	}
+}
+
+//Adds an update function to be called by the loop.
+//Returns the Updater it was added as, for removing it with RemoveUpdater.
+func AddUpdateFunc(updater func(dt time.Duration)) Updater {
+	u := updateFunc(updater)
+	AddUpdater(&u)
+	return &u
+}
+
+//Tracks the time a loop has yet to update for.
+type gameLoop struct {
+	opts LoopOptions
+	last time.Time
+	lag  time.Duration
+}
+
+func newGameLoop(opts LoopOptions, now time.Time) *gameLoop {
+	if opts.UpdateInterval <= 0 {
+		opts.UpdateInterval = time.Second / 60
+	}
+	if opts.DrawInterval <= 0 {
+		opts.DrawInterval = time.Duration(drawInterval)
+	}
+	if opts.MaxUpdates <= 0 {
+		opts.MaxUpdates = 5
+	}
+	if opts.MaxFrameTime <= 0 {
+		opts.MaxFrameTime = time.Second / 4
+	}
+	return &gameLoop{opts: opts, last: now}
+}
+
+//Runs the updates due by now and returns how far it is between the last update and the next.
+func (me *gameLoop) advance(now time.Time) float64 {
+	frame := now.Sub(me.last)
+	me.last = now
+	if frame > me.opts.MaxFrameTime {
+		frame = me.opts.MaxFrameTime
+	}
+	me.lag += frame
+	step := me.opts.UpdateInterval
+	updatersLock.Lock()
+	list := make([]Updater, len(updaters))
+	copy(list, updaters)
+	updatersLock.Unlock()
+	for n := 0; me.lag >= step; n++ {
+		if n == me.opts.MaxUpdates {
+			//fall behind rather than spiral
+			me.lag %= step
+			break
+		}
+		for _, u := range list {
+			u.Update(step)
+		}
+		me.lag -= step
+	}
+	return float64(me.lag) / float64(step)
+}
+
+//Blocks until CloseDisplay is called, updating the added Updaters at a fixed rate and drawing in between.
+//Drawers can smooth motion between updates with Canvas.Interpolation.
+func Run(opts LoopOptions) {
+	go func() {
+		loop := newGameLoop(opts, time.Now())
+		for running.Load() {
+			start := time.Now()
+			interpolation = loop.advance(start)
+			Draw()
+			if d := loop.opts.DrawInterval - time.Since(start); d > 0 {
+				time.Sleep(d)
+			}
+		}
+	}()
+	p.HandleEvents()
+}
+
+//Returns how far the current draw is between the last update and the next, from 0 to 1.
+//Drawers can draw moving things at their previous position plus this much of the way to their
+//current position to move smoothly when drawing more often than updating.
+//Always 0 when not running in Run.
+func (me *Canvas) Interpolation() float64 {
+	return interpolation
+}
@@ -0,0 +1,51 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGameLoop(t *testing.T) {
+	updates := 0
+	u := AddUpdateFunc(func(dt time.Duration) {
+		if dt != 10*time.Millisecond {
+			t.Errorf("Update got dt of %v, expected the UpdateInterval", dt)
+		}
+		updates++
+	})
+	defer RemoveUpdater(u)
+
+	start := time.Unix(0, 0)
+	loop := newGameLoop(LoopOptions{UpdateInterval: 10 * time.Millisecond, MaxUpdates: 3, MaxFrameTime: time.Second}, start)
+	if a := loop.advance(start.Add(25 * time.Millisecond)); updates != 2 || a != 0.5 {
+		t.Errorf("After 25ms: %d updates with interpolation %v, expected 2 and 0.5", updates, a)
+	}
+	if a := loop.advance(start.Add(30 * time.Millisecond)); updates != 3 || a != 0 {
+		t.Errorf("After 30ms: %d updates with interpolation %v, expected 3 and 0", updates, a)
+	}
+	//a long stall is capped at MaxUpdates and the rest is dropped
+	if a := loop.advance(start.Add(535 * time.Millisecond)); updates != 6 || a != 0.5 {
+		t.Errorf("After a stall: %d updates with interpolation %v, expected 6 and 0.5", updates, a)
+	}
+
+	RemoveUpdater(u)
+	loop.advance(start.Add(600 * time.Millisecond))
+	if updates != 6 {
+		t.Errorf("A removed Updater was updated")
+	}
+}
@@ -16,26 +16,32 @@
 package gfx
 
 import (
-	"io/ioutil"
-	l "log"
-	"os"
+	p "github.com/gtalent/starfish/plumbing"
+	"log/slog"
 )
 
-var log = l.New(ioutil.Discard, "  LOG: starfish backend: ", l.Ldate|l.Ltime)
-var errlog = l.New(ioutil.Discard, "ERROR: starfish backend: error: ", l.Ldate|l.Ltime)
+//Sets the Logger that all starfish packages log to. A nil Logger puts back the default one,
+//which discards all logs unless LogOn or ErrLogOn have turned them on.
+func SetLogger(l *slog.Logger) {
+	p.SetLogger(l)
+}
 
+//Turns logging of info and debug messages to stdout on or off. This only affects the default Logger,
+//so it does nothing while another one is set.
+//
+//Deprecated: Use SetLogger.
 func LogOn(on bool) {
-	if on {
-		log = l.New(os.Stdout, "  LOG: starfish backend: ", l.Ldate|l.Ltime)
-	} else {
-		log = l.New(ioutil.Discard, "  LOG: starfish backend: ", l.Ldate|l.Ltime)
-	}
+	p.LogOn(on)
 }
 
+//Turns logging of warnings and errors to stdout on or off. This only affects the default Logger,
+//so it does nothing while another one is set.
+//
+//Deprecated: Use SetLogger.
 func ErrLogOn(on bool) {
-	if on {
-		errlog = l.New(os.Stdout, "ERROR: starfish backend: ", l.Ldate|l.Ltime)
-	} else {
-		errlog = l.New(ioutil.Discard, "ERROR: starfish backend: ", l.Ldate|l.Ltime)
-	}
+	p.ErrLogOn(on)
+}
+
+func logger() *slog.Logger {
+	return p.Logger()
 }
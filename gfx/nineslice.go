@@ -0,0 +1,106 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	starfish "github.com/gtalent/starfish"
+)
+
+//How the edges and center of a NineSlice fill the space between its corners.
+const (
+	//Stretches the edges and center to fit.
+	NineSlice_Stretch int = iota
+	//Repeats the edges and center at their own size, cutting off the last repeat where it runs over.
+	NineSlice_Tile
+)
+
+//An image split into nine parts by four insets, for drawing panels and buttons at any size with Canvas.DrawNineSlice.
+//The corners are drawn at their own size, and the edges and center fill the space between them.
+type NineSlice struct {
+	//The image to draw, which is sliced within its clip rect.
+	Image *Image
+	//How far in from each side of the image the slices are.
+	Left, Top, Right, Bottom int
+	//How the edges and center are drawn, NineSlice_Stretch by default.
+	Mode int
+}
+
+//Returns a NineSlice of the image with the given insets that stretches its edges and center.
+func NewNineSlice(img *Image, left, top, right, bottom int) *NineSlice {
+	return &NineSlice{Image: img, Left: left, Top: top, Right: right, Bottom: bottom}
+}
+
+//Returns where a NineSlice of the given size with the given insets is cut,
+//scaling the insets down to fit when the size is smaller than them.
+func nineSliceCuts(start, size, before, after int) [4]int {
+	if before+after > size {
+		before = size * before / (before + after)
+		after = size - before
+	}
+	return [4]int{start, start + before, start + size - after, start + size}
+}
+
+//Draws the NineSlice filling the bounds on this Canvas.
+//Bounds smaller than the insets shrink the corners to fit.
+func (me *Canvas) DrawNineSlice(ns *NineSlice, bounds starfish.Bounds) {
+	img := ns.Image
+	if bounds.Width <= 0 || bounds.Height <= 0 || img.clipW() <= 0 || img.clipH() <= 0 {
+		return
+	}
+	srcX := nineSliceCuts(img.clipX(), img.clipW(), ns.Left, ns.Right)
+	srcY := nineSliceCuts(img.clipY(), img.clipH(), ns.Top, ns.Bottom)
+	destX := nineSliceCuts(bounds.X, bounds.Width, ns.Left, ns.Right)
+	destY := nineSliceCuts(bounds.Y, bounds.Height, ns.Top, ns.Bottom)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			src := starfish.Bounds{starfish.Point{srcX[col], srcY[row]}, starfish.Size{srcX[col+1] - srcX[col], srcY[row+1] - srcY[row]}}
+			dest := starfish.Bounds{starfish.Point{destX[col], destY[row]}, starfish.Size{destX[col+1] - destX[col], destY[row+1] - destY[row]}}
+			if src.Width <= 0 || src.Height <= 0 || dest.Width <= 0 || dest.Height <= 0 {
+				continue
+			}
+			tile := ns.Mode == NineSlice_Tile
+			me.drawSlice(img, src, dest, tile && col == 1, tile && row == 1)
+		}
+	}
+}
+
+//Draws the src rect of the image into the dest rect, stretched along each axis that is not tiled.
+//Tiles are cut off at the dest rect's edges rather than scaled, so they line up without seams.
+func (me *Canvas) drawSlice(img *Image, src, dest starfish.Bounds, tileX, tileY bool) {
+	stepW, stepH := dest.Width, dest.Height
+	if tileX {
+		stepW = src.Width
+	}
+	if tileY {
+		stepH = src.Height
+	}
+	for y := 0; y < dest.Height; y += stepH {
+		h := min(stepH, dest.Height-y)
+		srcH := src.Height
+		if tileY {
+			srcH = h
+		}
+		for x := 0; x < dest.Width; x += stepW {
+			w := min(stepW, dest.Width-x)
+			srcW := src.Width
+			if tileX {
+				srcW = w
+			}
+			me.DrawImageEx(img, starfish.Bounds{src.Point, starfish.Size{srcW, srcH}},
+				starfish.Bounds{starfish.Point{dest.X + x, dest.Y + y}, starfish.Size{w, h}}, Flip_None, 0, starfish.Point{})
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	starfish "../"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDrawNineSlice(t *testing.T) {
+	red, green := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}
+	f := drawFrame(56, 16, func(c *Canvas) {
+		//a 9x9 blue panel with black 3x3 corners, each with a red pixel at its inner corner,
+		//a green line across the middle of its top and left edges, and a white center
+		panel, err := NewTarget(9, 9)
+		if err != nil {
+			t.Fatal("NewTarget failed:", err)
+		}
+		defer panel.Free()
+		panel.DrawFunc(func(c *Canvas) {
+			c.SetRGB(0, 0, 255)
+			c.FillRect(0, 0, 9, 9)
+			for _, corner := range []image.Point{{0, 0}, {6, 0}, {0, 6}, {6, 6}} {
+				c.SetRGB(0, 0, 0)
+				c.FillRect(corner.X, corner.Y, 3, 3)
+			}
+			c.SetRGB(255, 0, 0)
+			for _, pt := range []image.Point{{2, 2}, {6, 2}, {2, 6}, {6, 6}} {
+				c.FillRect(pt.X, pt.Y, 1, 1)
+			}
+			c.SetRGB(0, 255, 0)
+			c.FillRect(4, 0, 1, 3)
+			c.FillRect(0, 4, 3, 1)
+			c.SetRGB(255, 255, 255)
+			c.FillRect(3, 3, 3, 3)
+		})
+		ns := NewNineSlice(&panel.Image, 3, 3, 3, 3)
+		c.DrawNineSlice(ns, starfish.Bounds{starfish.Point{0, 0}, starfish.Size{21, 15}})
+		ns.Mode = NineSlice_Tile
+		c.DrawNineSlice(ns, starfish.Bounds{starfish.Point{24, 0}, starfish.Size{21, 15}})
+		c.DrawNineSlice(ns, starfish.Bounds{starfish.Point{48, 0}, starfish.Size{4, 4}})
+	})
+	count := func(r image.Rectangle, want color.RGBA) (n int) {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if f.RGBAAt(x, y) == want {
+					n++
+				}
+			}
+		}
+		return
+	}
+	for _, x := range []int{0, 24} {
+		//the corners are drawn at their own size, so their red pixels stay single pixels at their inner corners
+		for _, pt := range []image.Point{{x + 2, 2}, {x + 18, 2}, {x + 2, 12}, {x + 18, 12}} {
+			if f.RGBAAt(pt.X, pt.Y) != red {
+				t.Errorf("The corner of a NineSlice does not have its red pixel at %v.", pt)
+			}
+		}
+		if n := count(image.Rect(x, 0, x+21, 15), red); n != 4 {
+			t.Errorf("The corners of a NineSlice at %d have %d red pixels, expected 4 unscaled ones.", x, n)
+		}
+		if n := count(image.Rect(x, 0, x+21, 15), color.RGBA{0, 0, 0, 255}); n != 4*8 {
+			t.Errorf("The corners of a NineSlice at %d have %d black pixels, expected 32 for 3x3 corners.", x, n)
+		}
+		if n := count(image.Rect(x+3, 3, x+18, 12), color.RGBA{255, 255, 255, 255}); n != 15*9 {
+			t.Errorf("The center of a NineSlice at %d covers %d pixels, expected it to fill the 135 between the corners.", x, n)
+		}
+	}
+	//stretched, the top edge's line is 5 pixels wide but still 3 high, and the left edge's 3 wide and 3 high
+	if n := count(image.Rect(0, 0, 21, 3), green); n != 5*3 || f.RGBAAt(8, 0) != green || f.RGBAAt(12, 2) != green {
+		t.Errorf("The stretched top edge of a NineSlice has %d green pixels, expected 15 from column 8 to 12.", n)
+	}
+	if n := count(image.Rect(0, 0, 3, 15), green); n != 3*3 || f.RGBAAt(0, 6) != green || f.RGBAAt(2, 8) != green {
+		t.Errorf("The stretched left edge of a NineSlice has %d green pixels, expected 9 from row 6 to 8.", n)
+	}
+	//tiled, the edges repeat every 3 pixels
+	for x := 27; x < 42; x++ {
+		if tiled := f.RGBAAt(x, 1) == green; tiled != ((x-27)%3 == 1) {
+			t.Errorf("The tiled top edge of a NineSlice is %v at column %d.", f.RGBAAt(x, 1), x)
+		}
+	}
+	for y := 3; y < 12; y++ {
+		if tiled := f.RGBAAt(25, y) == green; tiled != ((y-3)%3 == 1) {
+			t.Errorf("The tiled left edge of a NineSlice is %v at row %d.", f.RGBAAt(25, y), y)
+		}
+	}
+	if n := countDrawn(f, image.Rect(46, 0, 56, 16)); n != 4*4 {
+		t.Errorf("A NineSlice smaller than its corners covers %d pixels, expected the 16 of its bounds.", n)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+//Something the Canvas can fill shapes with: a solid Color, a LinearGradient or a RadialGradient.
+type Paint interface {
+	//Returns the colors of the paint in display coordinates, for a Canvas with the given transform.
+	image(m affine) image.Image
+}
+
+func (me Color) image(m affine) image.Image {
+	return image.NewUniform(color.NRGBA{me.Red, me.Green, me.Blue, me.Alpha})
+}
+
+//A color at a point along a gradient, where 0 is the start of the gradient and 1 is its end.
+type ColorStop struct {
+	Offset float64
+	Color  Color
+}
+
+//A gradient that blends between its color stops along the line from (X1, Y1) to (X2, Y2).
+//Points before the start of the line take the first stop's color, and points past its end take the last's.
+type LinearGradient struct {
+	X1, Y1, X2, Y2 float64
+	Stops          []ColorStop
+}
+
+//Returns a LinearGradient from (x1, y1) to (x2, y2) with the given color stops.
+func NewLinearGradient(x1, y1, x2, y2 float64, stops ...ColorStop) *LinearGradient {
+	return &LinearGradient{X1: x1, Y1: y1, X2: x2, Y2: y2, Stops: stops}
+}
+
+func (me *LinearGradient) image(m affine) image.Image {
+	dx, dy := me.X2-me.X1, me.Y2-me.Y1
+	length := dx*dx + dy*dy
+	return newGradientImage(me.Stops, m, func(x, y float64) float64 {
+		if length == 0 {
+			return 1
+		}
+		//project the point onto the line
+		return ((x-me.X1)*dx + (y-me.Y1)*dy) / length
+	})
+}
+
+//A gradient that blends between its color stops outward from (X, Y), reaching its last stop at Radius.
+type RadialGradient struct {
+	X, Y, Radius float64
+	Stops        []ColorStop
+}
+
+//Returns a RadialGradient centered on (x, y) with the given radius and color stops.
+func NewRadialGradient(x, y, radius float64, stops ...ColorStop) *RadialGradient {
+	return &RadialGradient{X: x, Y: y, Radius: radius, Stops: stops}
+}
+
+func (me *RadialGradient) image(m affine) image.Image {
+	return newGradientImage(me.Stops, m, func(x, y float64) float64 {
+		if me.Radius <= 0 {
+			return 1
+		}
+		return math.Hypot(x-me.X, y-me.Y) / me.Radius
+	})
+}
+
+//The number of colors a gradient is looked up from.
+const gradientSteps = 1024
+
+//An unbounded image of a gradient, which looks up the color of each pixel by the gradient's offset
+//at its center, mapped back from display coordinates to the gradient's.
+type gradientImage struct {
+	colors  [gradientSteps]color.RGBA
+	inverse affine
+	offset  func(x, y float64) float64
+}
+
+//Returns the image of the gradient with the given stops and offsets, drawn through the given transform.
+//A transform that flattens the plane leaves nothing to see, so it gives a transparent image.
+func newGradientImage(stops []ColorStop, m affine, offset func(x, y float64) float64) image.Image {
+	inverse, ok := m.invert()
+	if !ok {
+		return image.Transparent
+	}
+	me := &gradientImage{inverse: inverse, offset: offset}
+	sorted := append([]ColorStop(nil), stops...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+	for i := range me.colors {
+		me.colors[i] = stopColor(sorted, float64(i)/(gradientSteps-1))
+	}
+	return me
+}
+
+func (me *gradientImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (me *gradientImage) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (me *gradientImage) At(x, y int) color.Color {
+	pt := me.inverse.apply(p.PointF{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+	t := me.offset(pt.X, pt.Y)
+	i := int(math.Round(t * (gradientSteps - 1)))
+	if i < 0 || t != t {
+		i = 0
+	} else if i >= gradientSteps {
+		i = gradientSteps - 1
+	}
+	return me.colors[i]
+}
+
+//Returns the color at the offset t of the given sorted stops, blended with premultiplied alpha
+//so that stops fading to transparent do not darken.
+func stopColor(stops []ColorStop, t float64) color.RGBA {
+	if len(stops) == 0 {
+		return color.RGBA{}
+	}
+	if t <= stops[0].Offset {
+		return premultiply(stops[0].Color)
+	}
+	for i := 1; i < len(stops); i++ {
+		a, b := stops[i-1], stops[i]
+		if t >= b.Offset {
+			continue
+		}
+		f := (t - a.Offset) / (b.Offset - a.Offset)
+		ca, cb := premultiply(a.Color), premultiply(b.Color)
+		mix := func(x, y uint8) uint8 {
+			return uint8(math.Round(float64(x) + (float64(y)-float64(x))*f))
+		}
+		return color.RGBA{mix(ca.R, cb.R), mix(ca.G, cb.G), mix(ca.B, cb.B), mix(ca.A, cb.A)}
+	}
+	return premultiply(stops[len(stops)-1].Color)
+}
+
+func premultiply(c Color) color.RGBA {
+	r, g, b, a := color.NRGBA{c.Red, c.Green, c.Blue, c.Alpha}.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+}
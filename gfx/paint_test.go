@@ -0,0 +1,112 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestLinearGradient(t *testing.T) {
+	g := NewLinearGradient(0, 0, 10, 0,
+		ColorStop{Offset: 1, Color: Color{0, 0, 255, 255}},
+		ColorStop{Offset: 0, Color: Color{255, 0, 0, 255}})
+	img := g.image(translation(5, 5))
+	if c := img.At(0, 0); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Gradient before its start is %v, expected its first stop", c)
+	}
+	if c := img.At(20, 0); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("Gradient past its end is %v, expected its last stop", c)
+	}
+	if c := img.At(9, 100).(color.RGBA); c.R < 110 || c.R > 145 || c.B < 110 || c.B > 145 {
+		t.Errorf("Gradient at its middle is %v, expected an even blend", c)
+	}
+}
+
+func TestRadialGradient(t *testing.T) {
+	g := NewRadialGradient(0, 0, 10,
+		ColorStop{Offset: 0, Color: Color{255, 255, 255, 255}},
+		ColorStop{Offset: 1, Color: Color{255, 255, 255, 0}})
+	img := g.image(identity)
+	if c := img.At(0, 0).(color.RGBA); c.A < 230 {
+		t.Errorf("Gradient at its center is %v, expected its first stop", c)
+	}
+	if c := img.At(5, -1).(color.RGBA); c.A < 110 || c.A > 145 || c.R != c.A {
+		t.Errorf("Gradient at half its radius is %v, expected half transparent premultiplied white", c)
+	}
+	if c := img.At(-30, 0); c != (color.RGBA{}) {
+		t.Errorf("Gradient past its radius is %v, expected its last stop", c)
+	}
+}
+
+func TestCanvasGradientFill(t *testing.T) {
+	redToBlue := NewLinearGradient(0, 0, 20, 0,
+		ColorStop{Offset: 0, Color: Color{255, 0, 0, 255}},
+		ColorStop{Offset: 1, Color: Color{0, 0, 255, 255}})
+	f := drawFrame(64, 48, func(c *Canvas) {
+		c.PushViewport(20, 0, 40, 20)
+		c.SetPaint(redToBlue)
+		c.FillRect(0, 0, 20, 4)
+		c.SetRGB(0, 255, 0)
+		c.FillRect(0, 6, 20, 4)
+		c.PopViewport()
+
+		c.SetRGB(255, 255, 255)
+		c.FillRect(0, 20, 24, 24)
+		c.SetPaint(NewRadialGradient(12, 32, 10,
+			ColorStop{Offset: 0, Color: Color{255, 255, 255, 255}},
+			ColorStop{Offset: 1, Color: Color{255, 255, 255, 0}}))
+		c.FillCircle(12, 32, 10)
+
+		//turned a quarter clockwise, the gradient runs down the display
+		c.Translate(40, 24)
+		c.Rotate(90)
+		c.SetPaint(redToBlue)
+		c.FillRect(0, -4, 20, 8)
+	})
+	nearColor := func(got, want color.RGBA) bool {
+		d := func(a, b uint8) bool { return a-b < 12 || b-a < 12 }
+		return d(got.R, want.R) && d(got.G, want.G) && d(got.B, want.B) && got.A == want.A
+	}
+	if c := f.RGBAAt(20, 1); !nearColor(c, color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("A gradient fill in a viewport starts with %v, expected its first stop at the viewport's left.", c)
+	}
+	if c := f.RGBAAt(39, 1); !nearColor(c, color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("A gradient fill in a viewport ends with %v, expected its last stop.", c)
+	}
+	if c := f.RGBAAt(30, 1); !nearColor(c, color.RGBA{127, 0, 127, 255}) {
+		t.Errorf("A gradient fill is %v at its middle, expected an even blend.", c)
+	}
+	if f.RGBAAt(19, 1).A != 0 || f.RGBAAt(40, 1).A != 0 {
+		t.Error("A gradient fill paints outside of its shape.")
+	}
+	if c := f.RGBAAt(30, 7); c != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("A shape filled after SetRGB is %v, expected the color instead of the gradient.", c)
+	}
+	for y := 20; y < 44; y++ {
+		for x := 0; x < 24; x++ {
+			if c := f.RGBAAt(x, y); c != (color.RGBA{255, 255, 255, 255}) {
+				t.Fatalf("White fading to transparent over white is %v at (%d, %d), expected it not to darken.", c, x, y)
+			}
+		}
+	}
+	if c := f.RGBAAt(38, 24); !nearColor(c, color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("A rotated gradient fill starts with %v, expected its first stop at the top.", c)
+	}
+	if c := f.RGBAAt(38, 43); !nearColor(c, color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("A rotated gradient fill ends with %v, expected its last stop at the bottom.", c)
+	}
+}
@@ -0,0 +1,302 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"math"
+)
+
+//How far in pixels the lines that curves are drawn with may stray from the true curves.
+const curveTolerance = 0.1
+
+const (
+	pathMove = iota
+	pathLine
+	pathQuad
+	pathCubic
+	pathClose
+)
+
+type pathOp struct {
+	kind int
+	//the control points of curves, then the end point
+	pts [3]p.PointF
+}
+
+//A shape made of lines and curves, for filling and stroking with a Canvas.
+//Coordinates are in pixels, with (0, 0) at the top left corner of the top left pixel,
+//so a line through the middle of a row of pixels is at y + 0.5.
+//Curves are flattened when drawn, so they stay smooth at any size.
+type Path struct {
+	ops     []pathOp
+	started bool
+	start   p.PointF
+	current p.PointF
+}
+
+//Returns an empty Path.
+func NewPath() *Path {
+	return new(Path)
+}
+
+//Starts a new subpath at the given point.
+func (me *Path) MoveTo(x, y float64) {
+	me.ops = append(me.ops, pathOp{kind: pathMove, pts: [3]p.PointF{{X: x, Y: y}}})
+	me.started = true
+	me.start = p.PointF{X: x, Y: y}
+	me.current = me.start
+}
+
+//Adds a line from the current point to the given point.
+//Starts a new subpath at the given point if there is no current point.
+func (me *Path) LineTo(x, y float64) {
+	if !me.started {
+		me.MoveTo(x, y)
+		return
+	}
+	me.ops = append(me.ops, pathOp{kind: pathLine, pts: [3]p.PointF{{X: x, Y: y}}})
+	me.current = p.PointF{X: x, Y: y}
+}
+
+//Adds a quadratic Bezier curve from the current point to (x, y), bending toward the control point (cx, cy).
+func (me *Path) QuadTo(cx, cy, x, y float64) {
+	if !me.started {
+		me.MoveTo(cx, cy)
+	}
+	me.ops = append(me.ops, pathOp{kind: pathQuad, pts: [3]p.PointF{{X: cx, Y: cy}, {X: x, Y: y}}})
+	me.current = p.PointF{X: x, Y: y}
+}
+
+//Adds a cubic Bezier curve from the current point to (x, y), leaving toward (c1x, c1y) and arriving from (c2x, c2y).
+func (me *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float64) {
+	if !me.started {
+		me.MoveTo(c1x, c1y)
+	}
+	me.ops = append(me.ops, pathOp{kind: pathCubic, pts: [3]p.PointF{{X: c1x, Y: c1y}, {X: c2x, Y: c2y}, {X: x, Y: y}}})
+	me.current = p.PointF{X: x, Y: y}
+}
+
+//Adds an arc of the given radius that rounds the corner at (x1, y1) between the line from the current point
+//to (x1, y1) and the line from (x1, y1) to (x2, y2), joined to the current point by a line.
+//This is how rounded corners are made.
+func (me *Path) ArcTo(x1, y1, x2, y2, radius float64) {
+	if !me.started {
+		me.MoveTo(x1, y1)
+		return
+	}
+	corner := p.PointF{X: x1, Y: y1}
+	u1, l1 := unit(sub(me.current, corner))
+	u2, l2 := unit(sub(p.PointF{X: x2, Y: y2}, corner))
+	cross := u1.X*u2.Y - u1.Y*u2.X
+	if radius <= 0 || l1 == 0 || l2 == 0 || math.Abs(cross) < 1e-9 {
+		me.LineTo(x1, y1)
+		return
+	}
+	//the angle at the corner and the distance from it to where the arc touches the lines
+	theta := math.Acos(math.Max(-1, math.Min(1, dot(u1, u2))))
+	t := radius / math.Tan(theta/2)
+	from := add(corner, scale(u1, t))
+	bisector, _ := unit(add(u1, u2))
+	center := add(corner, scale(bisector, radius/math.Sin(theta/2)))
+
+	me.LineTo(from.X, from.Y)
+	start := math.Atan2(from.Y-center.Y, from.X-center.X)
+	sweep := math.Pi - theta
+	if cross > 0 {
+		sweep = -sweep
+	}
+	me.arc(center, radius, radius, start, sweep)
+}
+
+//Adds cubic Bezier curves along the ellipse with the given radii from the start angle through the sweep,
+//in radians.
+func (me *Path) arc(center p.PointF, rx, ry, start, sweep float64) {
+	n := int(math.Ceil(math.Abs(sweep) / (math.Pi / 2)))
+	if n < 1 {
+		return
+	}
+	step := sweep / float64(n)
+	//the distance of the control points from the ends of each curve, as a share of the radii
+	k := 4.0 / 3 * math.Tan(step/4)
+	a := start
+	for i := 0; i < n; i++ {
+		b := a + step
+		sa, ca := math.Sincos(a)
+		sb, cb := math.Sincos(b)
+		me.CubicTo(
+			center.X+rx*(ca-k*sa), center.Y+ry*(sa+k*ca),
+			center.X+rx*(cb+k*sb), center.Y+ry*(sb-k*cb),
+			center.X+rx*cb, center.Y+ry*sb)
+		a = b
+	}
+}
+
+//Adds a closed rectangle.
+func (me *Path) rect(x, y, w, h float64) {
+	me.MoveTo(x, y)
+	me.LineTo(x+w, y)
+	me.LineTo(x+w, y+h)
+	me.LineTo(x, y+h)
+	me.Close()
+}
+
+//Adds a closed rectangle with corners rounded to the given radius.
+func (me *Path) roundedRect(x, y, w, h, radius float64) {
+	radius = math.Min(radius, math.Min(w, h)/2)
+	if radius <= 0 {
+		me.rect(x, y, w, h)
+		return
+	}
+	me.MoveTo(x+radius, y)
+	me.LineTo(x+w-radius, y)
+	me.arc(p.PointF{X: x + w - radius, Y: y + radius}, radius, radius, -math.Pi/2, math.Pi/2)
+	me.LineTo(x+w, y+h-radius)
+	me.arc(p.PointF{X: x + w - radius, Y: y + h - radius}, radius, radius, 0, math.Pi/2)
+	me.LineTo(x+radius, y+h)
+	me.arc(p.PointF{X: x + radius, Y: y + h - radius}, radius, radius, math.Pi/2, math.Pi/2)
+	me.LineTo(x, y+radius)
+	me.arc(p.PointF{X: x + radius, Y: y + radius}, radius, radius, math.Pi, math.Pi/2)
+	me.Close()
+}
+
+//Adds a closed ellipse centered on (x, y) with the given radii.
+func (me *Path) ellipse(x, y, rx, ry float64) {
+	me.MoveTo(x+rx, y)
+	me.arc(p.PointF{X: x, Y: y}, rx, ry, 0, 2*math.Pi)
+	me.Close()
+}
+
+//Closes the current subpath with a line back to its start.
+func (me *Path) Close() {
+	if !me.started {
+		return
+	}
+	me.ops = append(me.ops, pathOp{kind: pathClose})
+	me.current = me.start
+}
+
+//A subpath flattened into lines.
+type polyline struct {
+	points []p.PointF
+	closed bool
+}
+
+//Returns the subpaths of this Path mapped through the given transform and flattened into lines.
+//Curves are flattened after being transformed, so they stay smooth however far they are scaled.
+func (me *Path) flatten(m affine) []polyline {
+	var lines []polyline
+	var cur *polyline
+	var last p.PointF
+	for _, op := range me.ops {
+		switch op.kind {
+		case pathMove:
+			lines = append(lines, polyline{})
+			cur = &lines[len(lines)-1]
+			last = m.apply(op.pts[0])
+			cur.points = append(cur.points, last)
+		case pathLine:
+			last = m.apply(op.pts[0])
+			cur.points = append(cur.points, last)
+		case pathQuad:
+			c, end := m.apply(op.pts[0]), m.apply(op.pts[1])
+			dd := length(add(sub(last, scale(c, 2)), end))
+			n := segments(dd / (8 * curveTolerance))
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				mt := 1 - t
+				cur.points = append(cur.points, p.PointF{
+					X: mt*mt*last.X + 2*mt*t*c.X + t*t*end.X,
+					Y: mt*mt*last.Y + 2*mt*t*c.Y + t*t*end.Y,
+				})
+			}
+			last = end
+		case pathCubic:
+			c1, c2, end := m.apply(op.pts[0]), m.apply(op.pts[1]), m.apply(op.pts[2])
+			dd := math.Max(length(add(sub(last, scale(c1, 2)), c2)), length(add(sub(c1, scale(c2, 2)), end)))
+			n := segments(3 * dd / (4 * curveTolerance))
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				mt := 1 - t
+				a, b, c, d := mt*mt*mt, 3*mt*mt*t, 3*mt*t*t, t*t*t
+				cur.points = append(cur.points, p.PointF{
+					X: a*last.X + b*c1.X + c*c2.X + d*end.X,
+					Y: a*last.Y + b*c1.Y + c*c2.Y + d*end.Y,
+				})
+			}
+			last = end
+		case pathClose:
+			cur.closed = true
+			last = cur.points[0]
+			//drawing on after closing starts a new subpath where the closed one started
+			lines = append(lines, polyline{points: []p.PointF{last}})
+			cur = &lines[len(lines)-1]
+		}
+	}
+	return lines
+}
+
+//Returns the number of lines to flatten a curve into, for the given square of the number needed.
+func segments(squared float64) int {
+	n := int(math.Ceil(math.Sqrt(squared)))
+	if n < 1 {
+		return 1
+	}
+	if n > 1000 {
+		return 1000
+	}
+	return n
+}
+
+//Returns the subpaths of this Path flattened into closed contours for filling.
+func (me *Path) contours(m affine) [][]p.PointF {
+	var list [][]p.PointF
+	for _, l := range me.flatten(m) {
+		if len(l.points) > 2 {
+			list = append(list, l.points)
+		}
+	}
+	return list
+}
+
+func add(a, b p.PointF) p.PointF {
+	return p.PointF{X: a.X + b.X, Y: a.Y + b.Y}
+}
+
+func sub(a, b p.PointF) p.PointF {
+	return p.PointF{X: a.X - b.X, Y: a.Y - b.Y}
+}
+
+func scale(a p.PointF, s float64) p.PointF {
+	return p.PointF{X: a.X * s, Y: a.Y * s}
+}
+
+func dot(a, b p.PointF) float64 {
+	return a.X*b.X + a.Y*b.Y
+}
+
+func length(a p.PointF) float64 {
+	return math.Hypot(a.X, a.Y)
+}
+
+//Returns the vector of length 1 in the direction of a, and the length of a.
+func unit(a p.PointF) (p.PointF, float64) {
+	l := length(a)
+	if l == 0 {
+		return a, 0
+	}
+	return scale(a, 1/l), l
+}
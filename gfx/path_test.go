@@ -0,0 +1,164 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"math"
+	"testing"
+)
+
+func near(a, b p.PointF) bool {
+	return math.Abs(a.X-b.X) < 1e-6 && math.Abs(a.Y-b.Y) < 1e-6
+}
+
+func TestPathFlatten(t *testing.T) {
+	path := NewPath()
+	path.MoveTo(0, 0)
+	path.QuadTo(10, 0, 10, 10)
+	path.CubicTo(10, 20, 0, 20, 0, 30)
+	path.Close()
+	lines := path.flatten(translation(5, 5))
+	if len(lines) == 0 || !lines[0].closed {
+		t.Fatal("Closed subpath was not flattened into a closed polyline.")
+	}
+	pts := lines[0].points
+	if !near(pts[0], p.PointF{X: 5, Y: 5}) || !near(pts[len(pts)-1], p.PointF{X: 5, Y: 35}) {
+		t.Errorf("Flattened path runs from %v to %v, expected (5, 5) to (5, 35)", pts[0], pts[len(pts)-1])
+	}
+	if len(pts) < 10 {
+		t.Errorf("Curves were flattened into only %d points", len(pts))
+	}
+}
+
+func TestPathArcTo(t *testing.T) {
+	path := NewPath()
+	path.MoveTo(0, 0)
+	path.ArcTo(10, 0, 10, 10, 4)
+	if !near(path.current, p.PointF{X: 10, Y: 4}) {
+		t.Errorf("ArcTo ended at %v, expected (10, 4)", path.current)
+	}
+	for _, pt := range path.flatten(identity)[0].points {
+		//every point of the rounded corner is 4 from its center
+		if pt.X > 6 && math.Abs(math.Hypot(pt.X-6, pt.Y-4)-4) > curveTolerance {
+			t.Errorf("ArcTo strays from its circle at %v", pt)
+		}
+	}
+}
+
+func TestDashes(t *testing.T) {
+	line := []p.PointF{{X: 0, Y: 0}, {X: 10, Y: 0}}
+	got := dashes(line, false, []float64{3, 2}, 1)
+	want := [][]p.PointF{
+		{{X: 0, Y: 0}, {X: 2, Y: 0}},
+		{{X: 4, Y: 0}, {X: 7, Y: 0}},
+		{{X: 9, Y: 0}, {X: 10, Y: 0}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Got dashes %v, expected %v", got, want)
+	}
+	for i := range want {
+		if len(got[i]) != 2 || !near(got[i][0], want[i][0]) || !near(got[i][1], want[i][1]) {
+			t.Errorf("Dash %d is %v, expected %v", i, got[i], want[i])
+		}
+	}
+}
+
+//Returns a path of straight lines through the given points.
+func polylinePath(points ...float64) *Path {
+	path := NewPath()
+	for i := 0; i+1 < len(points); i += 2 {
+		path.LineTo(points[i], points[i+1])
+	}
+	return path
+}
+
+func TestStrokePathCapsAndJoins(t *testing.T) {
+	f := drawFrame(64, 64, func(c *Canvas) {
+		c.SetRGB(255, 255, 255)
+		c.StrokePath(polylinePath(10, 8, 30, 8), StrokeOptions{Width: 6})
+		c.StrokePath(polylinePath(10, 20, 30, 20), StrokeOptions{Width: 6, Cap: LineCap_Square})
+		c.StrokePath(polylinePath(10, 32, 30, 32), StrokeOptions{Width: 6, Cap: LineCap_Round})
+		c.StrokePath(polylinePath(40, 10, 56, 10, 56, 26), StrokeOptions{Width: 6})
+		c.StrokePath(polylinePath(40, 40, 56, 40, 56, 56), StrokeOptions{Width: 6, Join: LineJoin_Bevel})
+		c.StrokePath(polylinePath(2, 50, 32, 50), StrokeOptions{Width: 2, Dashes: []float64{4, 2}})
+	})
+	//a butt cap ends at the end points, a square one half the width past them, and a round one
+	//rounds off the corners of the square one
+	if n := countDrawn(f, image.Rect(0, 0, 40, 14)); n != 20*6 || f.RGBAAt(10, 5).A == 0 || f.RGBAAt(29, 10).A == 0 {
+		t.Errorf("A butt capped stroke covers %d pixels, expected the 120 from its ends and 3 to each side.", n)
+	}
+	if n := countDrawn(f, image.Rect(0, 14, 40, 26)); n != 26*6 || f.RGBAAt(7, 17).A == 0 || f.RGBAAt(32, 22).A == 0 {
+		t.Errorf("A square capped stroke covers %d pixels, expected 156 reaching 3 past its ends.", n)
+	}
+	if f.RGBAAt(7, 32).A == 0 || f.RGBAAt(32, 32).A == 0 || f.RGBAAt(6, 32).A != 0 || f.RGBAAt(33, 32).A != 0 {
+		t.Error("A round capped stroke does not reach half its width past its ends.")
+	}
+	if f.RGBAAt(7, 29).A != 0 || f.RGBAAt(32, 34).A != 0 {
+		t.Error("A round capped stroke has square corners.")
+	}
+	if f.RGBAAt(58, 7).A == 0 {
+		t.Error("A mitered corner does not reach its point.")
+	}
+	if f.RGBAAt(58, 37).A != 0 || f.RGBAAt(56, 38).A == 0 {
+		t.Error("A beveled corner is not cut off flat.")
+	}
+	for x := 2; x < 32; x++ {
+		dash := (x-2)%6 < 4
+		if drawn := f.RGBAAt(x, 49).A != 0 && f.RGBAAt(x, 50).A != 0; drawn != dash {
+			t.Errorf("A dashed stroke at column %d is drawn %v, expected %v for dashes of 4 and gaps of 2.", x, drawn, dash)
+		}
+	}
+}
+
+func TestFillAndStrokePathBlendOnce(t *testing.T) {
+	box := NewPath()
+	box.MoveTo(10, 2)
+	box.ArcTo(26, 2, 26, 18, 8)
+	box.ArcTo(26, 18, 2, 18, 8)
+	box.ArcTo(2, 18, 2, 2, 8)
+	box.ArcTo(2, 2, 26, 2, 8)
+	box.Close()
+	f := drawFrame(64, 48, func(c *Canvas) {
+		c.SetRGBA(255, 0, 0, 128)
+		c.FillPath(box)
+		//a stroke crossing itself, and folding back over its own join
+		c.StrokePath(polylinePath(32, 10, 60, 10, 46, 4, 46, 28, 40, 20, 52, 20), StrokeOptions{Width: 3, Join: LineJoin_Round})
+		c.SetAntialias(true)
+		c.StrokePath(polylinePath(32, 44, 60, 44, 46, 38), StrokeOptions{Width: 3})
+	})
+	want := f.RGBAAt(14, 10)
+	if want.A < 126 || want.A > 130 {
+		t.Fatalf("A translucent path is %v, expected half opaque red.", want)
+	}
+	if f.RGBAAt(3, 3).A != 0 || f.RGBAAt(24, 16).A != 0 {
+		t.Error("FillPath fills outside of the rounded corners of its path.")
+	}
+	if f.RGBAAt(2, 10) != want || f.RGBAAt(14, 2) != want {
+		t.Error("FillPath does not fill up to the straight sides of its path.")
+	}
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			if c := f.RGBAAt(x, y); c.A > want.A {
+				t.Fatalf("A translucent path blends more than once at (%d, %d), making it %v.", x, y, c)
+			}
+		}
+	}
+	if f.RGBAAt(46, 10) != want || f.RGBAAt(46, 20) != want {
+		t.Error("A translucent stroke does not cover where it crosses itself.")
+	}
+}
@@ -0,0 +1,237 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	"fmt"
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//How many captured frames may wait to be encoded before frames are dropped.
+const recorderQueueSize = 32
+
+//The Recorder whose frame hook is set.
+var recorderLock sync.Mutex
+var recorder *Recorder
+
+//A captured frame and when it was drawn.
+type recordedFrame struct {
+	img  *image.RGBA
+	time time.Time
+}
+
+//Records frames drawn to the display as an animated GIF or a numbered sequence of PNGs.
+//Frames are read on the draw thread, but encoded on a separate goroutine so that drawing is not held up.
+//Only one Recorder can record at a time.
+type Recorder struct {
+	lock     sync.Mutex
+	path     string
+	every    int
+	duration time.Duration
+	start    time.Time
+	count    int
+	frames   chan recordedFrame
+	done     chan error
+	timer    *time.Timer
+}
+
+//Returns a Recorder that records every nth frame for the given duration.
+//If path ends in ".gif", the frames are written as an animated GIF at that path.
+//Otherwise, each frame is written to a PNG file named path followed by the frame number, e.g. path0000.png.
+func NewRecorder(path string, every int, duration time.Duration) *Recorder {
+	if every < 1 {
+		every = 1
+	}
+	r := new(Recorder)
+	r.path = path
+	r.every = every
+	r.duration = duration
+	return r
+}
+
+//Starts recording, stopping any other Recorder that is recording.
+//Recording stops on its own once the Recorder's duration has passed, whether or not frames are drawn.
+func (me *Recorder) Start() {
+	me.lock.Lock()
+	if me.frames != nil {
+		me.lock.Unlock()
+		return
+	}
+	me.start = time.Now()
+	me.count = 0
+	frames := make(chan recordedFrame, recorderQueueSize)
+	me.frames = frames
+	me.done = make(chan error, 1)
+	if strings.HasSuffix(strings.ToLower(me.path), ".gif") {
+		go me.encodeGIF(me.frames, me.done)
+	} else {
+		go me.encodePNGs(me.frames, me.done)
+	}
+	me.timer = time.AfterFunc(me.duration, func() {
+		me.lock.Lock()
+		defer me.lock.Unlock()
+		//the Recorder may have been stopped and started again since
+		if me.frames == frames {
+			me.stop()
+		}
+	})
+	recorderLock.Lock()
+	prev := recorder
+	recorder = me
+	p.SetFrameHook(me.frame)
+	recorderLock.Unlock()
+	me.lock.Unlock()
+	if prev != nil && prev != me {
+		prev.Stop()
+	}
+}
+
+//Stops recording. The frames already captured are still written.
+func (me *Recorder) Stop() {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	me.stop()
+}
+
+//Blocks until the recording has stopped and been written, then returns any error from writing it.
+func (me *Recorder) Wait() error {
+	me.lock.Lock()
+	done := me.done
+	me.lock.Unlock()
+	if done == nil {
+		return nil
+	}
+	err := <-done
+	done <- err
+	return err
+}
+
+//Must be called with the lock held.
+func (me *Recorder) stop() {
+	if me.frames == nil {
+		return
+	}
+	me.timer.Stop()
+	recorderLock.Lock()
+	//another Recorder may have replaced this one's frame hook
+	if recorder == me {
+		recorder = nil
+		p.SetFrameHook(nil)
+	}
+	recorderLock.Unlock()
+	close(me.frames)
+	me.frames = nil
+}
+
+//The frame hook, run on the draw thread.
+func (me *Recorder) frame(readPixels func() (*image.RGBA, error)) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if me.frames == nil {
+		return
+	}
+	now := time.Now()
+	if now.Sub(me.start) > me.duration {
+		me.stop()
+		return
+	}
+	me.count++
+	if (me.count-1)%me.every != 0 {
+		return
+	}
+	pix, err := readPixels()
+	if err != nil {
+		logger().Error("Recorder: Could not read frame", "path", me.path, "err", err)
+		return
+	}
+	//the pixels are reused for the next frame, and this one is kept until it is encoded
+	img := image.NewRGBA(pix.Rect)
+	copy(img.Pix, pix.Pix)
+	select {
+	case me.frames <- recordedFrame{img, now}:
+	default:
+		logger().Warn("Recorder: Dropped a frame, encoding is falling behind", "path", me.path)
+	}
+}
+
+func (me *Recorder) encodeGIF(frames chan recordedFrame, done chan error) {
+	var anim gif.GIF
+	var last time.Time
+	for f := range frames {
+		if len(anim.Delay) != 0 {
+			//GIF delays are in hundredths of a second
+			anim.Delay[len(anim.Delay)-1] = int(f.time.Sub(last) / (10 * time.Millisecond))
+		}
+		pal := image.NewPaletted(f.img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(pal, pal.Bounds(), f.img, f.img.Bounds().Min)
+		anim.Image = append(anim.Image, pal)
+		anim.Delay = append(anim.Delay, 0)
+		last = f.time
+	}
+	if len(anim.Image) == 0 {
+		done <- fmt.Errorf("no frames were recorded for %s", me.path)
+		return
+	}
+	if n := len(anim.Delay); n > 1 {
+		anim.Delay[n-1] = anim.Delay[n-2]
+	}
+	out, err := os.Create(me.path)
+	if err != nil {
+		done <- err
+		return
+	}
+	if err := gif.EncodeAll(out, &anim); err != nil {
+		out.Close()
+		done <- err
+		return
+	}
+	logger().Info("Recorder: Wrote frames", "path", me.path, "frames", len(anim.Image))
+	done <- out.Close()
+}
+
+func (me *Recorder) encodePNGs(frames chan recordedFrame, done chan error) {
+	var err error
+	n := 0
+	for f := range frames {
+		if err != nil {
+			continue
+		}
+		path := fmt.Sprintf("%s%04d.png", me.path, n)
+		var out *os.File
+		if out, err = os.Create(path); err != nil {
+			continue
+		}
+		if err = png.Encode(out, f.img); err != nil {
+			out.Close()
+			continue
+		}
+		err = out.Close()
+		n++
+	}
+	if err == nil {
+		logger().Info("Recorder: Wrote frames", "path", me.path+"*.png", "frames", n)
+	}
+	done <- err
+}
@@ -0,0 +1,122 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(8, 8, false)
+	dir := t.TempDir()
+
+	pngs := NewRecorder(filepath.Join(dir, "frame"), 2, time.Minute)
+	pngs.Start()
+	for i := 0; i < 5; i++ {
+		p.Draw()
+	}
+	pngs.Stop()
+	if err := pngs.Wait(); err != nil {
+		t.Fatal("Recorder could not write PNGs:", err)
+	}
+	for _, name := range []string{"frame0000.png", "frame0001.png", "frame0002.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error("Recorder did not write", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "frame0003.png")); err == nil {
+		t.Error("Recorder does not skip frames.")
+	}
+
+	path := filepath.Join(dir, "anim.gif")
+	anim := NewRecorder(path, 1, time.Minute)
+	anim.Start()
+	for i := 0; i < 3; i++ {
+		p.Draw()
+	}
+	anim.Stop()
+	if err := anim.Wait(); err != nil {
+		t.Fatal("Recorder could not write GIF:", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal("Recorder wrote an invalid GIF:", err)
+	}
+	if len(g.Image) != 3 {
+		t.Errorf("Recorder wrote %d GIF frames, should be 3.", len(g.Image))
+	}
+}
+
+func TestRecorderReplaced(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(8, 8, false)
+	dir := t.TempDir()
+
+	first := NewRecorder(filepath.Join(dir, "first"), 1, time.Minute)
+	second := NewRecorder(filepath.Join(dir, "second"), 1, time.Minute)
+	first.Start()
+	p.Draw()
+	second.Start()
+	waited := make(chan error)
+	go func() { waited <- first.Wait() }()
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Error("Replaced Recorder could not write PNGs:", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Starting a Recorder does not stop the one it replaces.")
+	}
+	first.Stop()
+	p.Draw()
+	second.Stop()
+	if err := second.Wait(); err != nil {
+		t.Fatal("Recorder could not write PNGs:", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "second0000.png")); err != nil {
+		t.Error("Stopping a replaced Recorder stops the one that replaced it.")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "first0001.png")); err == nil {
+		t.Error("A replaced Recorder keeps recording.")
+	}
+}
+
+func TestRecorderDuration(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(8, 8, false)
+
+	r := NewRecorder(filepath.Join(t.TempDir(), "frame"), 1, 10*time.Millisecond)
+	r.Start()
+	waited := make(chan error)
+	go func() { waited <- r.Wait() }()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		r.Stop()
+		t.Fatal("Recorder does not stop after its duration when no frames are drawn.")
+	}
+}
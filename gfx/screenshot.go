@@ -0,0 +1,90 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	"github.com/gtalent/starfish/input"
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var screenshotKeyLock sync.Mutex
+var screenshotKey *screenshotListener
+
+//Saves a screenshot to its directory when its key is pressed.
+type screenshotListener struct {
+	key int
+	dir string
+}
+
+func (me *screenshotListener) KeyPress(e input.KeyEvent) {
+	if e.Key != me.key {
+		return
+	}
+	path := filepath.Join(me.dir, "screenshot-"+time.Now().Format("20060102-150405.000")+".png")
+	//waiting for the next frame and saving it should not hold up events
+	go func() {
+		if err := SaveScreenshot(path); err != nil {
+			logger().Error("Could not save screenshot", "path", path, "err", err)
+		} else {
+			logger().Info("Saved screenshot", "path", path)
+		}
+	}()
+}
+
+//Waits for the next frame to be presented and returns it exactly as it was.
+//It must not be called from a Drawer or the goroutine that calls Draw, as that frame would never come.
+func Screenshot() (image.Image, error) {
+	return p.Screenshot()
+}
+
+//Waits for the next frame to be presented and saves it to a PNG file at the given path.
+//It must not be called from a Drawer or the goroutine that calls Draw.
+func SaveScreenshot(path string) error {
+	img, err := p.Screenshot()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//Binds the given key to save a screenshot into the given directory, named for the time it was taken.
+//Replaces any previously bound key. A key of -1 unbinds it.
+func SetScreenshotKey(key int, dir string) {
+	screenshotKeyLock.Lock()
+	defer screenshotKeyLock.Unlock()
+	if screenshotKey != nil {
+		input.RemoveKeyPressListener(screenshotKey)
+		screenshotKey = nil
+	}
+	if key != -1 {
+		screenshotKey = &screenshotListener{key: key, dir: dir}
+		input.AddKeyPressListener(screenshotKey)
+	}
+}
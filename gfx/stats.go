@@ -0,0 +1,144 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"sort"
+	"sync"
+	"time"
+)
+
+//The number of frames FrameStats keeps by default.
+const defaultStatsHistory = 120
+
+//How long a Drawer took to draw in a frame.
+type DrawerTiming struct {
+	Drawer Drawer
+	Time   time.Duration
+}
+
+//How long the parts of a frame took.
+type FrameTiming struct {
+	//When the frame started to be drawn.
+	Start time.Time
+	//The time since the start of the previous frame, or 0 for the first frame.
+	Interval time.Duration
+	//The time spent in all the Drawers and frame hooks.
+	Draw time.Duration
+	//The time spent presenting the frame.
+	Present time.Duration
+	//The time spent in each Drawer, in the order they were drawn.
+	Drawers []DrawerTiming
+}
+
+//Timings of the most recently drawn frames.
+type Stats struct {
+	//The frames per second over the frames in History.
+	FPS float64
+	//The most recent frames, oldest first.
+	History []FrameTiming
+}
+
+//Returns the frame interval that the given percent of the frames in History were drawn within.
+//Percentile(50) is the median and Percentile(99) shows the worst hitches.
+func (me Stats) Percentile(percent float64) time.Duration {
+	var intervals []time.Duration
+	for _, f := range me.History {
+		if f.Interval != 0 {
+			intervals = append(intervals, f.Interval)
+		}
+	}
+	if len(intervals) == 0 {
+		return 0
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	i := int(percent/100*float64(len(intervals))+0.5) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(intervals) {
+		i = len(intervals) - 1
+	}
+	return intervals[i]
+}
+
+//A ring buffer of FrameTimings.
+type frameStats struct {
+	lock    sync.Mutex
+	history []FrameTiming
+	next    int
+	full    bool
+	last    time.Time
+}
+
+var stats = frameStats{history: make([]FrameTiming, defaultStatsHistory)}
+
+//The time spent in each Drawer in the frame being drawn.
+//Written on the draw thread and read after it, both with drawLock held.
+var drawerTimes []DrawerTiming
+
+func (me *frameStats) record(start time.Time, timing p.FrameTiming, drawers []DrawerTiming) {
+	f := FrameTiming{Start: start, Draw: timing.Draw, Present: timing.Present}
+	f.Drawers = make([]DrawerTiming, len(drawers))
+	copy(f.Drawers, drawers)
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if !me.last.IsZero() {
+		f.Interval = start.Sub(me.last)
+	}
+	me.last = start
+	me.history[me.next] = f
+	me.next = (me.next + 1) % len(me.history)
+	if me.next == 0 {
+		me.full = true
+	}
+}
+
+//Returns the timings of the most recently drawn frames.
+func FrameStats() Stats {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	var s Stats
+	if stats.full {
+		s.History = append(s.History, stats.history[stats.next:]...)
+	}
+	s.History = append(s.History, stats.history[:stats.next]...)
+	var total time.Duration
+	frames := 0
+	for _, f := range s.History {
+		if f.Interval != 0 {
+			total += f.Interval
+			frames++
+		}
+	}
+	if total > 0 {
+		s.FPS = float64(frames) / total.Seconds()
+	}
+	return s
+}
+
+//Sets how many frames FrameStats keeps, clearing those already kept.
+func SetFrameStatsHistory(frames int) {
+	if frames < 1 {
+		frames = 1
+	}
+	stats.lock.Lock()
+	stats.history = make([]FrameTiming, frames)
+	stats.next = 0
+	stats.full = false
+	stats.last = time.Time{}
+	stats.lock.Unlock()
+}
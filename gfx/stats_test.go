@@ -0,0 +1,99 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"testing"
+	"time"
+)
+
+type sleepDrawer time.Duration
+
+func (me sleepDrawer) Draw(c *Canvas) {
+	time.Sleep(time.Duration(me))
+}
+
+func TestFrameStats(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	if err := OpenDisplay(8, 8, false); err != nil {
+		t.Fatal("OpenDisplay failed:", err)
+	}
+	defer CloseDisplay()
+	d := sleepDrawer(time.Millisecond)
+	AddDrawer(d)
+	defer RemoveDrawer(d)
+	SetFrameStatsHistory(2)
+	defer SetFrameStatsHistory(defaultStatsHistory)
+
+	for i := 0; i < 3; i++ {
+		Draw()
+	}
+	s := FrameStats()
+	if len(s.History) != 2 {
+		t.Fatalf("FrameStats kept %d frames, expected 2", len(s.History))
+	}
+	if !s.History[0].Start.Before(s.History[1].Start) {
+		t.Error("FrameStats history is not oldest first")
+	}
+	f := s.History[1]
+	if len(f.Drawers) != 1 {
+		t.Fatalf("Drawer timings are %v, expected one", f.Drawers)
+	}
+	if f.Drawers[0].Drawer != Drawer(d) || f.Drawers[0].Time < time.Millisecond {
+		t.Errorf("Drawer timings are %v, expected one of at least 1ms", f.Drawers)
+	}
+	if f.Draw < f.Drawers[0].Time || f.Interval < time.Millisecond {
+		t.Errorf("Frame timing %+v is shorter than its Drawer", f)
+	}
+	if s.FPS <= 0 || s.FPS > 1000 {
+		t.Errorf("FPS is %v, expected it to be over 0 and at most 1000", s.FPS)
+	}
+	if p50 := s.Percentile(50); p50 < time.Millisecond {
+		t.Errorf("Median frame interval is %v, expected at least 1ms", p50)
+	}
+}
+
+func TestFrameStatsConcurrentDraws(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	if err := OpenDisplay(8, 8, false); err != nil {
+		t.Fatal("OpenDisplay failed:", err)
+	}
+	defer CloseDisplay()
+	d := sleepDrawer(0)
+	AddDrawer(d)
+	defer RemoveDrawer(d)
+	//forget the frames drawn by earlier tests
+	SetFrameStatsHistory(defaultStatsHistory)
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for j := 0; j < 10; j++ {
+				Draw()
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	for _, f := range FrameStats().History {
+		if len(f.Drawers) != 1 {
+			t.Fatalf("A frame drawn alongside others recorded %d Drawer timings, expected 1", len(f.Drawers))
+		}
+	}
+}
@@ -0,0 +1,283 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"math"
+)
+
+//How the corners between the lines of a stroke are drawn.
+const (
+	//Corners are extended to a point, unless that is further than the miter limit.
+	LineJoin_Miter int = iota
+	LineJoin_Round
+	//Corners are cut off flat.
+	LineJoin_Bevel
+)
+
+//How the ends of the lines of a stroke are drawn.
+const (
+	//Lines end flat at their end points.
+	LineCap_Butt int = iota
+	LineCap_Round
+	//Lines end flat, half their width past their end points.
+	LineCap_Square
+)
+
+//Settings for Canvas.StrokePath.
+type StrokeOptions struct {
+	//The width of the stroke in pixels. Defaults to the line width of the Canvas.
+	Width float64
+	//One of the LineJoin constants. Defaults to LineJoin_Miter.
+	Join int
+	//One of the LineCap constants. Defaults to LineCap_Butt.
+	Cap int
+	//How far a mitered corner may reach, as a multiple of half the width, before it is beveled instead.
+	//Defaults to 10.
+	MiterLimit float64
+	//The lengths of the dashes and the gaps between them, alternating. No dashes draws a solid stroke.
+	Dashes []float64
+	//How far into the dash pattern the stroke starts.
+	DashOffset float64
+}
+
+//Returns the outline of the stroke of the given lines as contours to fill by the nonzero rule.
+func strokeContours(lines []polyline, opts StrokeOptions) [][]p.PointF {
+	s := stroker{opts: opts, hw: opts.Width / 2}
+	for _, l := range lines {
+		if len(l.points) < 2 && !l.closed {
+			//a subpath that is only a MoveTo draws nothing
+			continue
+		}
+		pts := dedupe(l.points)
+		closed := l.closed
+		if closed && len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+			pts = pts[:len(pts)-1]
+		}
+		if len(opts.Dashes) != 0 {
+			for _, dash := range dashes(pts, closed, opts.Dashes, opts.DashOffset) {
+				s.polyline(dash, false)
+			}
+		} else {
+			s.polyline(pts, closed)
+		}
+	}
+	return s.out
+}
+
+//Removes consecutive repeats of points.
+func dedupe(points []p.PointF) []p.PointF {
+	var out []p.PointF
+	for i, pt := range points {
+		if i == 0 || pt != points[i-1] {
+			out = append(out, pt)
+		}
+	}
+	return out
+}
+
+//Splits lines into the dashes of the pattern.
+func dashes(points []p.PointF, closed bool, pattern []float64, offset float64) [][]p.PointF {
+	if closed && len(points) > 0 {
+		points = append(append([]p.PointF{}, points...), points[0])
+	}
+	//an odd number of lengths is repeated so that dashes and gaps alternate
+	if len(pattern)%2 == 1 {
+		pattern = append(pattern[:len(pattern):len(pattern)], pattern...)
+	}
+	lengths := make([]float64, len(pattern))
+	total := 0.0
+	for i, d := range pattern {
+		lengths[i] = math.Abs(d)
+		total += lengths[i]
+	}
+	pattern = lengths
+	if total == 0 {
+		return [][]p.PointF{points}
+	}
+	//find where in the pattern the stroke starts
+	i := 0
+	left := math.Mod(offset, total)
+	if left < 0 {
+		left += total
+	}
+	for left >= pattern[i] {
+		left -= pattern[i]
+		i = (i + 1) % len(pattern)
+	}
+	left = pattern[i] - left
+
+	var out [][]p.PointF
+	var dash []p.PointF
+	on := i%2 == 0
+	if on && len(points) > 0 {
+		dash = []p.PointF{points[0]}
+	}
+	for j := 1; j < len(points); j++ {
+		a, b := points[j-1], points[j]
+		d, l := unit(sub(b, a))
+		pos := 0.0
+		for l-pos > left {
+			pos += left
+			pt := add(a, scale(d, pos))
+			if on {
+				out = append(out, append(dash, pt))
+				dash = nil
+			} else {
+				dash = []p.PointF{pt}
+			}
+			on = !on
+			i = (i + 1) % len(pattern)
+			left = pattern[i]
+		}
+		left -= l - pos
+		if on {
+			dash = append(dash, b)
+		}
+	}
+	if on && len(dash) > 1 {
+		out = append(out, dash)
+	}
+	return out
+}
+
+//Builds the outline of a stroke out of pieces that are filled together by the nonzero rule.
+type stroker struct {
+	opts StrokeOptions
+	//half the width of the stroke
+	hw  float64
+	out [][]p.PointF
+}
+
+//Adds the outline of the stroke of the given lines.
+func (me *stroker) polyline(points []p.PointF, closed bool) {
+	n := len(points)
+	if n == 0 {
+		return
+	}
+	if n == 1 {
+		//a line of no length only shows its caps
+		switch me.opts.Cap {
+		case LineCap_Round:
+			me.circle(points[0])
+		case LineCap_Square:
+			h := me.hw
+			c := points[0]
+			me.add([]p.PointF{{X: c.X - h, Y: c.Y - h}, {X: c.X + h, Y: c.Y - h}, {X: c.X + h, Y: c.Y + h}, {X: c.X - h, Y: c.Y + h}})
+		}
+		return
+	}
+	for i := 1; i < n; i++ {
+		me.segment(points[i-1], points[i])
+	}
+	for i := 1; i < n-1; i++ {
+		me.join(points[i-1], points[i], points[i+1])
+	}
+	if closed {
+		me.segment(points[n-1], points[0])
+		me.join(points[n-2], points[n-1], points[0])
+		me.join(points[n-1], points[0], points[1])
+	} else {
+		me.cap(points[0], points[1])
+		me.cap(points[n-1], points[n-2])
+	}
+}
+
+//Returns the normal to the line from a to b, half the width of the stroke long.
+func (me *stroker) normal(a, b p.PointF) p.PointF {
+	d, _ := unit(sub(b, a))
+	return p.PointF{X: -d.Y * me.hw, Y: d.X * me.hw}
+}
+
+func (me *stroker) segment(a, b p.PointF) {
+	n := me.normal(a, b)
+	me.add([]p.PointF{add(a, n), add(b, n), sub(b, n), sub(a, n)})
+}
+
+//Fills the outside of the corner at v between the lines from a and to b.
+func (me *stroker) join(a, v, b p.PointF) {
+	d1, _ := unit(sub(v, a))
+	d2, _ := unit(sub(b, v))
+	cross := d1.X*d2.Y - d1.Y*d2.X
+	if math.Abs(cross) < 1e-9 && dot(d1, d2) > 0 {
+		//no corner
+		return
+	}
+	if me.opts.Join == LineJoin_Round {
+		me.circle(v)
+		return
+	}
+	n1, n2 := me.normal(a, v), me.normal(v, b)
+	if cross > 0 {
+		//the outside of the corner is on the other side
+		n1, n2 = scale(n1, -1), scale(n2, -1)
+	}
+	o1, o2 := add(v, n1), add(v, n2)
+	if me.opts.Join == LineJoin_Miter {
+		cosHalf := math.Sqrt((1 + dot(d1, d2)) / 2)
+		if cosHalf > 0 && 1/cosHalf <= me.opts.MiterLimit {
+			m, _ := unit(add(n1, n2))
+			me.add([]p.PointF{v, o1, add(v, scale(m, me.hw/cosHalf)), o2})
+			return
+		}
+	}
+	me.add([]p.PointF{v, o1, o2})
+}
+
+//Adds the cap at the end point e of the line from from.
+func (me *stroker) cap(e, from p.PointF) {
+	switch me.opts.Cap {
+	case LineCap_Round:
+		me.circle(e)
+	case LineCap_Square:
+		d, _ := unit(sub(e, from))
+		n := me.normal(from, e)
+		ext := scale(d, me.hw)
+		me.add([]p.PointF{add(e, n), add(add(e, n), ext), add(sub(e, n), ext), sub(e, n)})
+	}
+}
+
+//Adds a circle the width of the stroke.
+func (me *stroker) circle(c p.PointF) {
+	n := 8
+	if me.hw > curveTolerance {
+		n = int(math.Max(8, math.Ceil(math.Pi/math.Acos(1-curveTolerance/me.hw))))
+	}
+	pts := make([]p.PointF, n)
+	for i := range pts {
+		s, co := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		pts[i] = p.PointF{X: c.X + me.hw*co, Y: c.Y + me.hw*s}
+	}
+	me.add(pts)
+}
+
+//Adds a piece of the outline, wound the same way as the other pieces so that they join.
+func (me *stroker) add(poly []p.PointF) {
+	area := 0.0
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		area += poly[j].X*poly[i].Y - poly[i].X*poly[j].Y
+	}
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+			poly[i], poly[j] = poly[j], poly[i]
+		}
+	}
+	me.out = append(me.out, poly)
+}
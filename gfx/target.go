@@ -0,0 +1,66 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import p "github.com/gtalent/starfish/plumbing"
+
+//An offscreen Image that can be drawn into like the display.
+//Minimaps, cached backgrounds, and the like can be drawn once into a Target,
+//then drawn as its Image.
+type Target struct {
+	Image
+	canvas Canvas
+}
+
+//Creates a transparent Target of the given size.
+func NewTarget(width, height int) (*Target, error) {
+	i, err := p.CreateTarget(width, height)
+	if err != nil {
+		return nil, err
+	}
+	t := new(Target)
+	t.img = i
+	t.target = true
+	t.ResetClipRect()
+	t.ResetSize()
+	t.canvas = newCanvas()
+	t.canvas.viewport.list[0].Width = width
+	t.canvas.viewport.list[0].Height = height
+	return t, nil
+}
+
+//Runs the given Drawer with a Canvas that draws into this Target.
+//The contents of the Target are kept between draws.
+func (me *Target) Draw(drawer Drawer) {
+	p.DrawTo(me.img, func() {
+		me.canvas.load()
+		drawer.Draw(&me.canvas)
+		me.canvas.end(drawer)
+	})
+}
+
+//Calls the given function with a Canvas that draws into this Target.
+//The contents of the Target are kept between draws.
+func (me *Target) DrawFunc(drawer func(*Canvas)) {
+	me.Draw(drawFunc(drawer))
+}
+
+//Clears this Target to transparent.
+func (me *Target) Clear() {
+	p.DrawTo(me.img, func() {
+		p.Clear(p.Color{})
+	})
+}
@@ -0,0 +1,55 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"image/color"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	h := p.NewHeadless()
+	p.SetBackend(h)
+	p.OpenDisplay(40, 40, false)
+
+	target, err := NewTarget(10, 10)
+	if err != nil {
+		t.Fatal("NewTarget failed:", err)
+	}
+	target.DrawFunc(func(c *Canvas) {
+		c.SetRGB(255, 0, 0)
+		c.FillRect(0, 0, 5, 10)
+		c.PushViewport(5, 0, 5, 10)
+		c.SetRGB(0, 255, 0)
+		c.FillRect(0, 0, 100, 100)
+		c.PopViewport()
+	})
+	h.Draw(func() {
+		c := newCanvas()
+		c.load()
+		c.DrawImage(&target.Image, 20, 20)
+	})
+	target.Free()
+
+	f := h.Frame()
+	if f.RGBAAt(19, 20).A != 0 || f.RGBAAt(30, 20).A != 0 {
+		t.Error("Target draws beyond its size.")
+	}
+	if f.RGBAAt(20, 20) != (color.RGBA{255, 0, 0, 255}) || f.RGBAAt(29, 29) != (color.RGBA{0, 255, 0, 255}) {
+		t.Error("Target does not draw what was drawn into it.")
+	}
+}
@@ -31,10 +31,9 @@ func (me *fontKey) String() string {
 }
 
 var fonts = newFlyweight(
-	func(me *flyweight, key key) interface{} {
+	func(me *flyweight, key key) (interface{}, error) {
 		k := key.(*fontKey)
-		font := b.LoadFont(k.path, k.size)
-		return font
+		return b.LoadFont(k.path, k.size)
 	},
 	func(me *flyweight, path key, val interface{}) {
 		b.FreeFont(val.(*b.Font))
@@ -73,18 +72,21 @@ type Font struct {
 	color Color
 }
 
-//Loads the TrueType Font at the given path, or nil if the font was not found.
-func LoadFont(path string, size int) (font *Font) {
+//Loads the TrueType Font at the given path.
+//The error can be checked for ErrNotFound, ErrDecode, and the like with errors.Is.
+func LoadFont(path string, size int) (*Font, error) {
 	var key fontKey
 	key.path = path
 	key.size = size
 
-	if f := fonts.checkout(&key); f != nil {
-		font = new(Font)
-		font.font = f.(*b.Font)
-		font.key = key
+	f, err := fonts.checkout(&key)
+	if err != nil {
+		return nil, err
 	}
-	return
+	font := new(Font)
+	font.font = f.(*b.Font)
+	font.key = key
+	return font, nil
 }
 
 //Sets the color that this Font will draw with.
@@ -101,18 +103,19 @@ func (me *Font) SetRGB(red, green, blue byte) {
 }
 
 //Loads text into the Text object passed in.
-//Returns true if successful, false otherwise.
-func (me *Font) WriteTo(text string, t *Text) bool {
+func (me *Font) WriteTo(text string, t *Text) error {
 	t.color = me.color
 	t.text = new(b.Image)
 	return me.font.WriteTo(text, t.text, me.color.bColor())
 }
 
 //Returns a Text object representing the given string.
-func (me *Font) Write(text string) *Text {
+func (me *Font) Write(text string) (*Text, error) {
 	t := new(Text)
-	me.WriteTo(text, t)
-	return t
+	if err := me.WriteTo(text, t); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
 //Returns the size of this font.
@@ -0,0 +1,86 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	starfish "github.com/gtalent/starfish"
+	p "github.com/gtalent/starfish/plumbing"
+	"math"
+)
+
+//A 2D affine transform, which maps (x, y) to (a*x + c*y + e, b*x + d*y + f).
+type affine struct {
+	a, b, c, d, e, f float64
+}
+
+var identity = affine{a: 1, d: 1}
+
+func translation(x, y float64) affine {
+	return affine{a: 1, d: 1, e: x, f: y}
+}
+
+func scaling(sx, sy float64) affine {
+	return affine{a: sx, d: sy}
+}
+
+//Returns a rotation by the given radians, clockwise as y points down.
+func rotation(radians float64) affine {
+	s, c := math.Sincos(radians)
+	return affine{a: c, b: s, c: -s, d: c}
+}
+
+//Returns the transform that applies n, then this transform.
+func (me affine) mul(n affine) affine {
+	return affine{
+		a: me.a*n.a + me.c*n.b,
+		b: me.b*n.a + me.d*n.b,
+		c: me.a*n.c + me.c*n.d,
+		d: me.b*n.c + me.d*n.d,
+		e: me.a*n.e + me.c*n.f + me.e,
+		f: me.b*n.e + me.d*n.f + me.f,
+	}
+}
+
+func (me affine) apply(pt p.PointF) p.PointF {
+	return p.PointF{X: me.a*pt.X + me.c*pt.Y + me.e, Y: me.b*pt.X + me.d*pt.Y + me.f}
+}
+
+//Returns the transform that undoes this one, or false if it flattens the plane and cannot be undone.
+func (me affine) invert() (affine, bool) {
+	det := me.a*me.d - me.b*me.c
+	if det == 0 {
+		return affine{}, false
+	}
+	inv := affine{a: me.d / det, b: -me.b / det, c: -me.c / det, d: me.a / det}
+	inv.e = -(inv.a*me.e + inv.c*me.f)
+	inv.f = -(inv.b*me.e + inv.d*me.f)
+	return inv, true
+}
+
+//Returns the whole pixels this transform moves by, and whether it does nothing else.
+func (me affine) offset() (starfish.Point, bool) {
+	if me.a != 1 || me.b != 0 || me.c != 0 || me.d != 1 || me.e != math.Trunc(me.e) || me.f != math.Trunc(me.f) {
+		return starfish.Point{}, false
+	}
+	return starfish.Point{X: int(me.e), Y: int(me.f)}, true
+}
+
+//Returns the most this transform stretches any length by.
+func (me affine) scale() float64 {
+	s := me.a*me.a + me.b*me.b + me.c*me.c + me.d*me.d
+	det := me.a*me.d - me.b*me.c
+	return math.Sqrt((s + math.Sqrt(math.Max(s*s-4*det*det, 0))) / 2)
+}
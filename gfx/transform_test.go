@@ -0,0 +1,144 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package gfx
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestAffine(t *testing.T) {
+	m := translation(10, 0).mul(rotation(math.Pi / 2)).mul(scaling(2, 2))
+	if pt := m.apply(p.PointF{X: 1, Y: 0}); !near(pt, p.PointF{X: 10, Y: 2}) {
+		t.Errorf("Transform maps (1, 0) to %v, expected (10, 2)", pt)
+	}
+	inv, ok := m.invert()
+	if !ok {
+		t.Fatal("Transform could not be inverted")
+	}
+	if pt := inv.apply(p.PointF{X: 10, Y: 2}); !near(pt, p.PointF{X: 1, Y: 0}) {
+		t.Errorf("Inverse maps (10, 2) to %v, expected (1, 0)", pt)
+	}
+	if s := m.scale(); math.Abs(s-2) > 1e-9 {
+		t.Errorf("Transform scales by %v, expected 2", s)
+	}
+	if _, ok := scaling(0, 1).invert(); ok {
+		t.Error("Flattening transform was inverted")
+	}
+}
+
+func TestAffineOffset(t *testing.T) {
+	if o, ok := translation(3, -4).offset(); !ok || o.X != 3 || o.Y != -4 {
+		t.Errorf("Whole pixel translation gives offset %v, %v", o, ok)
+	}
+	if _, ok := translation(0.5, 0).offset(); ok {
+		t.Error("Part pixel translation gives an offset")
+	}
+	if _, ok := rotation(math.Pi).offset(); ok {
+		t.Error("Rotation gives an offset")
+	}
+}
+
+func TestCanvasSaveRestore(t *testing.T) {
+	c := newCanvas()
+	c.Translate(5, 5)
+	c.Save()
+	c.Rotate(90)
+	c.Scale(2, 2)
+	c.Restore()
+	if o, ok := c.transform.offset(); !ok || o.X != 5 || o.Y != 5 {
+		t.Errorf("Restore did not bring back the saved transform, got %+v", c.transform)
+	}
+	c.Restore()
+	if o, ok := c.transform.offset(); !ok || o.X != 5 || o.Y != 5 {
+		t.Error("Restore with nothing saved changed the transform")
+	}
+}
+
+func TestCanvasTransformedDrawing(t *testing.T) {
+	red, green, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}
+	f := drawFrame(64, 32, func(c *Canvas) {
+		img := newCornerTarget(t)
+		defer img.Free()
+		c.Save()
+		c.Translate(10, 10)
+		c.DrawImage(&img.Image, 0, 0)
+		c.Restore()
+		c.Save()
+		c.Translate(30, 10)
+		c.Rotate(90)
+		c.DrawImage(&img.Image, 0, 0)
+		c.Restore()
+		c.Save()
+		c.Translate(40, 10)
+		c.Scale(2, 2)
+		c.DrawImage(&img.Image, 0, 0)
+		c.Restore()
+
+		c.SetRGB(255, 255, 0)
+		c.Save()
+		c.Translate(2, 20)
+		c.Scale(2, 1)
+		c.FillRect(0, 0, 3, 3)
+		c.Restore()
+		c.Save()
+		c.Translate(60, 30)
+		c.Rotate(180)
+		c.FillRect(0, 0, 4, 3)
+		c.Restore()
+		c.FillRect(20, 20, 2, 2)
+	})
+	tests := []struct {
+		x, y int
+		want color.RGBA
+		what string
+	}{
+		{10, 10, red, "translated image's top left"},
+		{12, 10, green, "translated image's top right"},
+		{10, 11, blue, "translated image's bottom left"},
+		//turned a quarter clockwise, the top of the image runs down its right side
+		{29, 10, red, "rotated image's top left"},
+		{29, 12, green, "rotated image's top right"},
+		{28, 10, blue, "rotated image's bottom left"},
+		{40, 10, red, "scaled image's top left"},
+		{41, 11, red, "scaled image's top left"},
+		{44, 11, green, "scaled image's top right"},
+		{41, 13, blue, "scaled image's bottom left"},
+	}
+	for _, test := range tests {
+		if c := f.RGBAAt(test.x, test.y); c != test.want {
+			t.Errorf("The %s is %v at (%d, %d), expected %v.", test.what, c, test.x, test.y, test.want)
+		}
+	}
+	if n := countDrawn(f, image.Rect(26, 8, 32, 16)); n != 6 {
+		t.Errorf("A rotated 3x2 image covers %d pixels, expected 6.", n)
+	}
+	if n := countDrawn(f, image.Rect(38, 8, 48, 16)); n != 24 {
+		t.Errorf("A 3x2 image scaled by 2 covers %d pixels, expected 24.", n)
+	}
+	if n := countDrawn(f, image.Rect(0, 18, 16, 32)); n != 18 || f.RGBAAt(7, 22).A == 0 {
+		t.Errorf("A 3x3 rect scaled twice as wide covers %d pixels, expected 18 from (2, 20) to (7, 22).", n)
+	}
+	if n := countDrawn(f, image.Rect(50, 24, 64, 32)); n != 12 || f.RGBAAt(56, 27).A == 0 || f.RGBAAt(59, 29).A == 0 {
+		t.Errorf("A 4x3 rect turned half around (60, 30) covers %d pixels, expected 12 from (56, 27) to (59, 29).", n)
+	}
+	if n := countDrawn(f, image.Rect(18, 18, 24, 24)); n != 4 || f.RGBAAt(20, 20).A == 0 {
+		t.Error("Restore does not bring back the transform from before Save.")
+	}
+}
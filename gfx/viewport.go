@@ -19,18 +19,17 @@ import (
 	starfish "github.com/gtalent/starfish"
 )
 
+//A stack of viewports, which grows as deep as they are pushed.
 type viewport struct {
-	list         [500]starfish.Bounds
-	translations [500]starfish.Point
+	list         []starfish.Bounds
+	translations []starfish.Point
 	pt           uint
 }
 
 func newViewport() (v viewport) {
 	v.pt = 0
-	v.list[0].X = 0
-	v.list[0].Y = 0
-	v.list[0].Width = -1
-	v.list[0].Height = -1
+	v.list = []starfish.Bounds{{Size: starfish.Size{Width: -1, Height: -1}}}
+	v.translations = []starfish.Point{{}}
 	return
 }
 
@@ -39,12 +38,30 @@ func (me *viewport) translate() starfish.Point {
 }
 
 func (me *viewport) bounds() starfish.Bounds {
-	return me.list[me.pt]
+	return me.at(me.pt)
+}
+
+//Returns the bounds at the given depth.
+//The base viewport's size of -1 is the current size of the display, so it follows the window as it is resized.
+func (me *viewport) at(pt uint) starfish.Bounds {
+	b := me.list[pt]
+	if b.Width == -1 {
+		b.Width = DisplayWidth()
+	}
+	if b.Height == -1 {
+		b.Height = DisplayHeight()
+	}
+	return b
 }
 
 func (me *viewport) push(rect starfish.Bounds) {
 	me.pt++
-	me.list[me.pt] = rect
+	if int(me.pt) == len(me.list) {
+		me.list = append(me.list, rect)
+		me.translations = append(me.translations, starfish.Point{})
+	} else {
+		me.list[me.pt] = rect
+	}
 	me.calcBounds()
 }
 
@@ -59,17 +76,12 @@ func (me *viewport) calcBounds() {
 	if me.pt == 0 {
 		return
 	}
-	p := me.list[me.pt-1]
+	p := me.at(me.pt - 1)
 	n := &me.list[me.pt]
 	t := &me.translations[me.pt]
 	*t = me.translations[me.pt-1]
 	n.Point.AddTo(p.Point)
 
-	if me.pt == 1 { // base viewport
-		p.Width = DisplayWidth()
-		p.Height = DisplayHeight()
-	}
-
 	//make sure the point of origin is not negative
 	if n.X < p.X {
 		t.X = n.X - p.X
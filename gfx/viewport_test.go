@@ -17,10 +17,16 @@ package gfx
 
 import (
 	starfish "../"
+	"bytes"
+	p "github.com/gtalent/starfish/plumbing"
+	"log/slog"
+	"strings"
 	"testing"
 )
 
 func TestViewportPushPop(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(800, 600, false)
 	viewport := newViewport()
 	initial := viewport.bounds()
 	tests := make([]starfish.Bounds, 0)
@@ -37,3 +43,122 @@ func TestViewportPushPop(t *testing.T) {
 		}
 	}
 }
+
+func TestViewportFollowsResize(t *testing.T) {
+	h := p.NewHeadless()
+	p.SetBackend(h)
+	p.OpenDisplay(800, 600, false)
+	viewport := newViewport()
+	h.Resize(320, 240)
+	if b := viewport.bounds(); b.Width != 320 || b.Height != 240 {
+		t.Errorf("Base viewport is %v after resizing the display to 320x240", b.Size)
+	}
+	viewport.push(starfish.Bounds{starfish.Point{300, 200}, starfish.Size{100, 100}})
+	if b := viewport.bounds(); b.Width != 20 || b.Height != 40 {
+		t.Errorf("Pushed viewport is %v, expected it to be clipped to the resized display", b.Size)
+	}
+}
+
+func TestViewportClipsBelowTop(t *testing.T) {
+	h := p.NewHeadless()
+	p.SetBackend(h)
+	p.OpenDisplay(20, 20, false)
+	h.Draw(func() {
+		c := newCanvas()
+		c.load()
+		c.PushViewport(3, 7, 5, 4)
+		c.SetRGB(255, 0, 0)
+		c.FillRect(-10, -10, 40, 40)
+		c.PopViewport()
+	})
+	f := h.Frame()
+	if f.RGBAAt(3, 7).A == 0 || f.RGBAAt(7, 10).A == 0 {
+		t.Error("A viewport below the top of the display does not draw inside of itself.")
+	}
+	if f.RGBAAt(3, 6).A != 0 || f.RGBAAt(3, 11).A != 0 || f.RGBAAt(8, 7).A != 0 {
+		t.Error("A viewport below the top of the display does not clip to itself.")
+	}
+}
+
+func TestViewportDeepStack(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(800, 600, false)
+	viewport := newViewport()
+	for i := 0; i < 1000; i++ {
+		viewport.push(starfish.Bounds{starfish.Point{0, 0}, starfish.Size{800 - i/2, 600}})
+	}
+	if b := viewport.bounds(); b.Width != 800-999/2 {
+		t.Errorf("Viewport 1000 deep is %v", b)
+	}
+	for i := 0; i < 1000; i++ {
+		viewport.pop()
+	}
+	viewport.push(starfish.Bounds{starfish.Point{1, 1}, starfish.Size{10, 10}})
+	if b := viewport.bounds(); b.X != 1 || b.Width != 10 {
+		t.Errorf("Viewport pushed after popping a deep stack is %v", b)
+	}
+}
+
+func TestCanvasSaveRestoreState(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(800, 600, false)
+	c := newCanvas()
+	c.SetRGB(1, 2, 3)
+	c.SetLineWidth(2)
+	c.Save()
+	c.SetRGB(4, 5, 6)
+	c.SetLineWidth(5)
+	c.SetAntialias(true)
+	c.PushViewport(10, 10, 100, 100)
+	c.PushViewport(10, 10, 50, 50)
+	c.Restore()
+	if c.color != (Color{1, 2, 3, 255}) || c.lineWidth != 2 || c.antialias {
+		t.Errorf("Restore did not bring back the saved color, line width and antialiasing")
+	}
+	if c.viewport.pt != 0 || c.origin != (starfish.Point{}) {
+		t.Errorf("Restore did not pop the viewports pushed since Save")
+	}
+}
+
+func TestCanvasEndResetsUnbalanced(t *testing.T) {
+	p.SetBackend(p.NewHeadless())
+	p.OpenDisplay(800, 600, false)
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer SetLogger(nil)
+
+	c := newCanvas()
+	unbalance := func() {
+		c.Save()
+		c.Translate(3, 3)
+		c.SetLineWidth(4)
+		c.Save()
+		c.PushViewport(10, 10, 100, 100)
+	}
+	unbalance()
+	c.end(nil)
+	out := buf.String()
+	if !strings.Contains(out, "saves=2") || !strings.Contains(out, "viewports=1") {
+		t.Errorf("Unbalanced saves and viewports were not reported, got: %q", out)
+	}
+	if len(c.states) != 0 || c.viewport.pt != 0 || c.transform != identity || c.lineWidth != 1 || c.origin != (starfish.Point{}) {
+		t.Error("Unbalanced saves and viewports were not reset to the state before the first save")
+	}
+
+	//reports are limited to one per interval, but the stacks are reset every time
+	buf.Reset()
+	unbalance()
+	c.end(nil)
+	if buf.Len() != 0 {
+		t.Errorf("Unbalanced saves and viewports were reported again within the interval, got: %q", buf.String())
+	}
+	if len(c.states) != 0 || c.viewport.pt != 0 {
+		t.Error("Unbalanced saves and viewports were not reset while reports were limited")
+	}
+	c.lastWarn = c.lastWarn.Add(-unbalancedWarnInterval)
+	unbalance()
+	c.end(nil)
+	if n := strings.Count(buf.String(), "unbalanced"); n != 1 {
+		t.Errorf("Unbalanced saves and viewports were reported %d times after the interval, expected once", n)
+	}
+}
@@ -77,5 +77,16 @@ func init() {
 		}
 		mouseReleaseListenersLock.Unlock()
 	}
+	b.WindowFunc = func(e b.WindowEvent) {
+		var we WindowEvent
+		we.Type = e.Type
+		we.Width = e.Width
+		we.Height = e.Height
+		windowListenersLock.Lock()
+		for _, v := range windowListeners {
+			go v.WindowChange(we)
+		}
+		windowListenersLock.Unlock()
+	}
 	go b.HandleInput()
 }
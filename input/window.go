@@ -0,0 +1,88 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package input
+
+import (
+	p "github.com/gtalent/starfish/plumbing"
+	"sync"
+)
+
+//Types of WindowEvent.
+const (
+	//The window changed size.
+	Window_Resized     int = p.Window_Resized
+	Window_FocusGained     = p.Window_FocusGained
+	Window_FocusLost       = p.Window_FocusLost
+	Window_Minimized       = p.Window_Minimized
+	Window_Maximized       = p.Window_Maximized
+	//The window was restored from being minimized or maximized.
+	Window_Restored = p.Window_Restored
+	//The mouse entered the window.
+	Window_MouseEnter = p.Window_MouseEnter
+	//The mouse left the window.
+	Window_MouseLeave = p.Window_MouseLeave
+	//Part of the window was uncovered and needs to be redrawn.
+	Window_Exposed = p.Window_Exposed
+)
+
+type WindowEvent struct {
+	Type int
+	//The new size of the display, for Window_Resized.
+	Width, Height int
+}
+
+var windowListenersLock sync.Mutex
+var windowListeners []WindowListener
+
+type WindowListener interface {
+	WindowChange(WindowEvent)
+}
+
+type genericWindowListener struct {
+	f func(WindowEvent)
+}
+
+func (me *genericWindowListener) WindowChange(e WindowEvent) {
+	me.f(e)
+}
+
+//Adds a function to be called when the window changes.
+//Returns the listener the function was added as, for removing it with RemoveWindowListener.
+func AddWindowFunc(listener func(WindowEvent)) WindowListener {
+	l := &genericWindowListener{listener}
+	AddWindowListener(l)
+	return l
+}
+
+//Adds an interface to be called when the window is resized, gains or loses focus, and the like.
+func AddWindowListener(listener WindowListener) {
+	windowListenersLock.Lock()
+	windowListeners = append(windowListeners, listener)
+	windowListenersLock.Unlock()
+}
+
+//Removes the specified window listener.
+func RemoveWindowListener(listener WindowListener) {
+	windowListenersLock.Lock()
+	defer windowListenersLock.Unlock()
+	for i, v := range windowListeners {
+		if v == listener {
+			windowListeners[i] = windowListeners[len(windowListeners)-1]
+			windowListeners = windowListeners[:len(windowListeners)-1]
+			break
+		}
+	}
+}
@@ -0,0 +1,560 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+//The foundation that starfish draws and gets input through.
+//Backends report input and changes to the window by passing events to PostEvent from within HandleEvents.
+type Backend interface {
+	//DISPLAY
+
+	OpenDisplay(opts DisplayOptions) error
+	CloseDisplay()
+	SetDisplayTitle(title string)
+	SetDisplayIcon(icon image.Image) error
+	DisplaySize() (w, h int)
+	//Runs f on the thread that owns the display, then presents the frame.
+	Draw(f func())
+	//Runs f on the thread that owns the display with drawing directed into the given target.
+	DrawTo(target *Image, f func())
+	//Fills the whole of the current drawing target with the given color.
+	Clear(c Color)
+	//Returns a copy of what has been drawn to the current drawing target, read into dst
+	//if it is the target's size, or else into a new image. Dst may be nil.
+	ReadPixels(dst *image.RGBA) (*image.RGBA, error)
+
+	//TEXTURES
+
+	LoadImage(path string) (*Image, error)
+	//Creates a transparent Image of the given size that can be drawn into with DrawTo.
+	CreateTarget(w, h int) (*Image, error)
+	FreeImage(img *Image)
+	//Returns a new Image of img stretched to w by h and rotated by angle degrees clockwise, blended with the
+	//given Filter_ constant. The new Image has its own texture, which must be freed on its own.
+	ResizeAngleOf(img *Image, angle float64, w, h, filter int) (*Image, error)
+	//Returns the size of the texture backing the given Image.
+	TextureSize(img *Image) (w, h int)
+
+	//FONTS
+
+	LoadFont(path string, size int) (*Font, error)
+	FreeFont(font *Font)
+	//Renders the text into t.
+	WriteText(font *Font, text string, t *Image, c Color) error
+
+	//PRIMITIVES
+
+	//Limits drawing to the given bounds, in pixels from the top left corner of the drawing target.
+	//A negative width or height means the whole target.
+	SetClipRect(x, y, w, h int)
+	FillRect(x, y, w, h int, c Color)
+	SetPaint(paint image.Image)
+	SetBlendMode(mode int)
+	SetImageColor(c Color)
+	FillRoundedRect(x, y, w, h, radius int, c Color)
+	DrawRoundedRect(x, y, w, h, radius, width int, c Color)
+	//Draws a line from (x1, y1) to (x2, y2) inclusive, width pixels thick.
+	DrawLine(x1, y1, x2, y2, width int, c Color, antialias bool)
+	//Fills the ellipse centered on (x, y) that reaches rx pixels to the sides and ry pixels up and down.
+	FillEllipse(x, y, rx, ry int, c Color, antialias bool)
+	//Draws a 1 pixel wide outline of the ellipse.
+	DrawEllipse(x, y, rx, ry int, c Color, antialias bool)
+	//Draws a 1 pixel wide outline of the circle from start to end degrees,
+	//going clockwise from the positive x axis.
+	DrawArc(x, y, radius, start, end int, c Color, antialias bool)
+	//Fills the circle from start to end degrees, going clockwise from the positive x axis.
+	FillPie(x, y, radius, start, end int, c Color, antialias bool)
+	//Fills the pixels whose centers are inside of the polygon by the given FillRule.
+	FillPolygon(points []image.Point, rule int, c Color, antialias bool)
+	//Fills the inside of the path made of the given closed contours by the given FillRule.
+	FillPath(contours [][]PointF, rule int, c Color, antialias bool)
+	DrawImage(img *Image, destX, destY, srcX, srcY, srcW, srcH int)
+	DrawImageQuad(img *Image, srcX, srcY, srcW, srcH int, quad [4]PointF)
+	DrawImageEx(img *Image, srcX, srcY, srcW, srcH, destX, destY, destW, destH, flip int, angle float64, pivotX, pivotY int)
+
+	//EVENTS
+
+	//Blocks handling events until the display is asked to quit.
+	HandleEvents()
+}
+
+var backend Backend
+
+//Settings for opening the display.
+type DisplayOptions struct {
+	//The size of the window. A width or height of 0 uses the screen's, or 800x600 for the Headless Backend.
+	Width, Height int
+	Fullscreen    bool
+	//Lets the user resize the window.
+	Resizable bool
+	//Opens the window without a border or title bar.
+	Borderless bool
+	//Waits for the screen's refresh before presenting each frame.
+	VSync bool
+	//Uses a software renderer instead of a hardware accelerated one.
+	Software bool
+	//Draws at the full resolution of high-DPI screens.
+	//The display size and mouse coordinates are then in pixels rather than screen points.
+	HighDPI bool
+	//The smallest size the window can be resized to. 0 means no minimum.
+	MinWidth, MinHeight int
+	//The initial position of the window's top left corner. Nil lets the window manager place it.
+	Position *image.Point
+	//The icon of the window. Nil keeps the default icon.
+	Icon image.Image
+	//The path of an image to use as the icon of the window, if Icon is nil.
+	IconPath string
+}
+
+//Sets the Backend that starfish will use. Must be called before OpenDisplay.
+func SetBackend(b Backend) {
+	failFrameRequests()
+	backend = b
+}
+
+//Returns the Backend that starfish is currently using.
+func GetBackend() Backend {
+	return backend
+}
+
+var drawFunc = func() {}
+var drawLock sync.Mutex
+var frameHookLock sync.Mutex
+var frameHook func(func() (*image.RGBA, error))
+
+//The frames Screenshot is waiting for, guarded by frameHookLock.
+var frameRequests []chan frameResult
+
+//The buffer frames are read back into, reused from frame to frame. Only used while drawing.
+var framePix *image.RGBA
+
+type frameResult struct {
+	img *image.RGBA
+	err error
+}
+
+//An RGB color representation.
+type Color struct {
+	Red, Green, Blue, Alpha byte
+}
+
+//A point with fractional coordinates, where (0, 0) is the top left corner of the top left pixel
+//and (0.5, 0.5) is its center.
+type PointF struct {
+	X, Y float64
+}
+
+//An image held by the Backend that loaded it.
+type Image struct {
+	//The Backend specific texture data.
+	Texture       interface{}
+	Width, Height int
+}
+
+//Returns an identifier for the texture backing the given Image, for logging.
+func textureID(img *Image) string {
+	if img == nil || img.Texture == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%p", img.Texture)
+}
+
+func (me *Image) W() int {
+	w, _ := backend.TextureSize(me)
+	return w
+}
+
+func (me *Image) H() int {
+	_, h := backend.TextureSize(me)
+	return h
+}
+
+//A font held by the Backend that loaded it.
+type Font struct {
+	//The Backend specific font data.
+	Face interface{}
+}
+
+func OpenDisplay(w, h int, full bool) error {
+	return OpenDisplayWithOptions(DisplayOptions{Width: w, Height: h, Fullscreen: full})
+}
+
+func OpenDisplayWithOptions(opts DisplayOptions) error {
+	if opts.Icon == nil && opts.IconPath != "" {
+		icon, err := loadIcon(opts.IconPath)
+		if err != nil {
+			Logger().Error("Could not open display", "width", opts.Width, "height", opts.Height, "fullscreen", opts.Fullscreen, "err", err)
+			return err
+		}
+		opts.Icon = icon
+	}
+	err := backend.OpenDisplay(opts)
+	if err != nil {
+		Logger().Error("Could not open display", "width", opts.Width, "height", opts.Height, "fullscreen", opts.Fullscreen, "err", err)
+		return err
+	}
+	w, h := backend.DisplaySize()
+	Logger().Info("Opened display", "width", w, "height", h, "fullscreen", opts.Fullscreen)
+	return nil
+}
+
+//Decodes the icon at the given path for OpenDisplayWithOptions.
+func loadIcon(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &Error{Kind: ErrNotFound, Op: "OpenDisplay", Path: path, Msg: err.Error()}
+	}
+	defer file.Close()
+	icon, _, err := image.Decode(file)
+	if err != nil {
+		return nil, &Error{Kind: ErrDecode, Op: "OpenDisplay", Path: path, Msg: err.Error()}
+	}
+	return icon, nil
+}
+
+func CloseDisplay() {
+	backend.CloseDisplay()
+	failFrameRequests()
+}
+
+func SetDrawFunc(f func()) {
+	drawFunc = f
+}
+
+//Sets the title of the window.
+func SetDisplayTitle(title string) {
+	backend.SetDisplayTitle(title)
+}
+
+//Sets the icon of the window.
+func SetDisplayIcon(icon image.Image) error {
+	return backend.SetDisplayIcon(icon)
+}
+
+//Returns the width of the display window.
+func DisplayWidth() int {
+	w, _ := backend.DisplaySize()
+	return w
+}
+
+//Returns the height of the display window.
+func DisplayHeight() int {
+	_, h := backend.DisplaySize()
+	return h
+}
+
+//Sets a function to be called on the draw thread after each frame is drawn and before it is presented.
+//It is passed a function that reads back the pixels of the frame, only when it is called, and at most once
+//per frame. The pixels are reused for the next frame, so they must not be changed or kept after the hook
+//returns. A nil hook removes it.
+func SetFrameHook(hook func(readPixels func() (*image.RGBA, error))) {
+	frameHookLock.Lock()
+	frameHook = hook
+	frameHookLock.Unlock()
+}
+
+//How long the parts of drawing a frame took.
+type FrameTiming struct {
+	//The time spent in the draw function.
+	Draw time.Duration
+	//The time spent reading the frame back for Screenshot and the frame hook, running the hook,
+	//presenting the frame, and passing it to and from the draw thread.
+	Present time.Duration
+}
+
+//Used to manually draw the screen. Returns how long it took.
+func Draw() FrameTiming {
+	drawLock.Lock()
+	defer drawLock.Unlock()
+	var timing FrameTiming
+	start := time.Now()
+	backend.Draw(func() {
+		drawStart := time.Now()
+		drawFunc()
+		timing.Draw = time.Since(drawStart)
+		frameHookLock.Lock()
+		hook, requests := frameHook, frameRequests
+		frameRequests = nil
+		frameHookLock.Unlock()
+		//read back as the frame is about to be presented, so it is exactly what goes on screen,
+		//and only if something wants it
+		read := false
+		var err error
+		readPixels := func() (*image.RGBA, error) {
+			if !read {
+				framePix, err = backend.ReadPixels(framePix)
+				read = true
+			}
+			return framePix, err
+		}
+		for _, r := range requests {
+			img, err := readPixels()
+			if err == nil {
+				img = copyRGBA(img)
+			}
+			r <- frameResult{img, err}
+		}
+		if hook != nil {
+			hook(readPixels)
+		}
+	})
+	timing.Present = time.Since(start) - timing.Draw
+	return timing
+}
+
+//Waits for the next frame to be presented and returns a copy of it exactly as it was.
+//It must not be called while holding up the drawing of frames, such as from the goroutine that calls Draw.
+//Returns an error if the display is closed or the backend changed before then.
+func Screenshot() (*image.RGBA, error) {
+	r := make(chan frameResult, 1)
+	frameHookLock.Lock()
+	frameRequests = append(frameRequests, r)
+	frameHookLock.Unlock()
+	f := <-r
+	return f.img, f.err
+}
+
+//Fails the Screenshots waiting for a frame that will not be drawn.
+func failFrameRequests() {
+	frameHookLock.Lock()
+	requests := frameRequests
+	frameRequests = nil
+	frameHookLock.Unlock()
+	for _, r := range requests {
+		r <- frameResult{nil, errors.New("the display was closed before the next frame")}
+	}
+}
+
+func copyRGBA(img *image.RGBA) *image.RGBA {
+	c := image.NewRGBA(img.Rect)
+	copy(c.Pix, img.Pix)
+	return c
+}
+
+//Draws into the given target instead of the screen for the duration of f.
+func DrawTo(target *Image, f func()) {
+	backend.DrawTo(target, f)
+}
+
+func Clear(c Color) {
+	backend.Clear(c)
+}
+
+//Returns a copy of what has been drawn into the given target.
+func ReadTarget(target *Image) (img *image.RGBA, err error) {
+	err = &Error{Kind: ErrTexture, Op: "ReadTarget", Msg: "image is not a target"}
+	backend.DrawTo(target, func() {
+		img, err = backend.ReadPixels(nil)
+	})
+	return
+}
+
+func LoadImage(path string) (*Image, error) {
+	img, err := backend.LoadImage(path)
+	if err != nil {
+		Logger().Warn("Could not load image", "path", path, "err", err)
+		return nil, err
+	}
+	Logger().Debug("Loaded image", "path", path, "width", img.Width, "height", img.Height, "texture", textureID(img))
+	return img, nil
+}
+
+func CreateTarget(w, h int) (*Image, error) {
+	img, err := backend.CreateTarget(w, h)
+	if err != nil {
+		Logger().Warn("Could not create target", "width", w, "height", h, "err", err)
+		return nil, err
+	}
+	Logger().Debug("Created target", "width", w, "height", h, "texture", textureID(img))
+	return img, nil
+}
+
+func FreeImage(img *Image) {
+	Logger().Debug("Freeing image", "texture", textureID(img))
+	backend.FreeImage(img)
+}
+
+//Returns a new Image of image stretched to width by height, then rotated by angle degrees clockwise around
+//its center, in the bounding box of the rotated image.
+func ResizeAngleOf(image *Image, angle float64, width, height, filter int) (*Image, error) {
+	img, err := backend.ResizeAngleOf(image, angle, width, height, filter)
+	if err != nil {
+		Logger().Warn("Could not resize image", "texture", textureID(image), "width", width, "height", height, "angle", angle, "err", err)
+		return nil, err
+	}
+	Logger().Debug("Resized image", "texture", textureID(img), "width", width, "height", height, "angle", angle, "filter", filter)
+	return img, nil
+}
+
+func LoadFont(path string, size int) (*Font, error) {
+	font, err := backend.LoadFont(path, size)
+	if err != nil {
+		Logger().Warn("Could not load font", "path", path, "size", size, "err", err)
+		return nil, err
+	}
+	Logger().Debug("Loaded font", "path", path, "size", size)
+	return font, nil
+}
+
+func FreeFont(val *Font) {
+	backend.FreeFont(val)
+}
+
+func (me *Font) WriteTo(text string, t *Image, c Color) error {
+	err := backend.WriteText(me, text, t, c)
+	if err != nil {
+		Logger().Warn("Could not write text", "text", text, "err", err)
+	}
+	return err
+}
+
+//Limits drawing to the given bounds, in pixels from the top left corner of the drawing target.
+func SetClipRect(x, y, w, h int) {
+	backend.SetClipRect(x, y, w, h)
+}
+
+//Makes FillRect, FillRoundedRect, FillEllipse, FillPie, FillPolygon and FillPath use the colors of
+//the given paint at each pixel instead of their own color, until it is set back to nil.
+func SetPaint(paint image.Image) {
+	backend.SetPaint(paint)
+}
+
+//Ways that what is drawn can be blended into what is under it.
+const (
+	//Blends by the alpha of what is drawn. The default.
+	BlendMode_Alpha int = iota
+	//Adds the color of what is drawn, scaled by its alpha, to what is under it, brightening it.
+	BlendMode_Add
+	//Multiplies what is under by the color of what is drawn, ignoring its alpha.
+	BlendMode_Modulate
+	//Multiplies what is under by the color of what is drawn, fading by its alpha.
+	BlendMode_Multiply
+	//Brightens what is under by the inverse of the color of what is drawn, the opposite of multiplying.
+	BlendMode_Screen
+	//Replaces what is under with what is drawn, alpha and all, without blending.
+	BlendMode_None
+)
+
+//Makes everything drawn after, shapes and images alike, blend into what is under it by the given mode.
+//Drawing into a target keeps the blend mode from before it once done.
+func SetBlendMode(mode int) {
+	backend.SetBlendMode(mode)
+}
+
+//Makes images drawn after have their colors and alpha multiplied by the given color,
+//until it is set back to opaque white. The images' textures are left as they were after each draw.
+func SetImageColor(c Color) {
+	backend.SetImageColor(c)
+}
+
+func FillRoundedRect(x, y, w, h, radius int, c Color) {
+	backend.FillRoundedRect(x, y, w, h, radius, c)
+}
+
+func DrawRoundedRect(x, y, w, h, radius, width int, c Color) {
+	backend.DrawRoundedRect(x, y, w, h, radius, width, c)
+}
+
+func FillRect(x, y, w, h int, c Color) {
+	backend.FillRect(x, y, w, h, c)
+}
+
+func DrawLine(x1, y1, x2, y2, width int, c Color, antialias bool) {
+	backend.DrawLine(x1, y1, x2, y2, width, c, antialias)
+}
+
+func FillEllipse(x, y, rx, ry int, c Color, antialias bool) {
+	backend.FillEllipse(x, y, rx, ry, c, antialias)
+}
+
+func DrawEllipse(x, y, rx, ry int, c Color, antialias bool) {
+	backend.DrawEllipse(x, y, rx, ry, c, antialias)
+}
+
+func DrawArc(x, y, radius, start, end int, c Color, antialias bool) {
+	backend.DrawArc(x, y, radius, start, end, c, antialias)
+}
+
+func FillPie(x, y, radius, start, end int, c Color, antialias bool) {
+	backend.FillPie(x, y, radius, start, end, c, antialias)
+}
+
+func FillPolygon(points []image.Point, rule int, c Color, antialias bool) {
+	backend.FillPolygon(points, rule, c, antialias)
+}
+
+func FillPath(contours [][]PointF, rule int, c Color, antialias bool) {
+	backend.FillPath(contours, rule, c, antialias)
+}
+
+//Draws the image at the given coordinates.
+func DrawImage(img *Image, destX, destY, srcX, srcY, srcW, srcH int) {
+	backend.DrawImage(img, destX, destY, srcX, srcY, srcW, srcH)
+}
+
+//Draws the source rect of the image stretched onto the parallelogram with the given corners,
+//which are where the source rect's top left, top right, bottom right and bottom left corners land.
+func DrawImageQuad(img *Image, srcX, srcY, srcW, srcH int, quad [4]PointF) {
+	backend.DrawImageQuad(img, srcX, srcY, srcW, srcH, quad)
+}
+
+//Ways to mirror an image drawn with DrawImageEx, which can be combined with |.
+const (
+	Flip_None int = 0
+	//Mirrors the image left to right.
+	Flip_Horizontal = 1
+	//Mirrors the image top to bottom.
+	Flip_Vertical = 2
+)
+
+//Draws the source rect of the image stretched to the destination rect, mirrored by flip, then rotated
+//by angle degrees clockwise around the pivot, which is relative to the destination rect's top left corner.
+func DrawImageEx(img *Image, srcX, srcY, srcW, srcH, destX, destY, destW, destH, flip int, angle float64, pivotX, pivotY int) {
+	backend.DrawImageEx(img, srcX, srcY, srcW, srcH, destX, destY, destW, destH, flip, angle, pivotX, pivotY)
+}
+
+//Returns where the corners of the source rect land for DrawImageEx, in the order DrawImageQuad takes them.
+func ImageExQuad(destX, destY, destW, destH, flip int, angle float64, pivotX, pivotY int) [4]PointF {
+	x1, y1 := float64(destX), float64(destY)
+	x2, y2 := x1+float64(destW), y1+float64(destH)
+	if flip&Flip_Horizontal != 0 {
+		x1, x2 = x2, x1
+	}
+	if flip&Flip_Vertical != 0 {
+		y1, y2 = y2, y1
+	}
+	quad := [4]PointF{{x1, y1}, {x2, y1}, {x2, y2}, {x1, y2}}
+	px, py := float64(destX+pivotX), float64(destY+pivotY)
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	for i, pt := range quad {
+		dx, dy := pt.X-px, pt.Y-py
+		quad[i] = PointF{px + dx*cos - dy*sin, py + dx*sin + dy*cos}
+	}
+	return quad
+}
+
+func HandleEvents() {
+	backend.HandleEvents()
+}
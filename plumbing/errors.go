@@ -0,0 +1,56 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+import "errors"
+
+//Kinds of errors reported by Backends. Check for them with errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrDecode     = errors.New("could not decode")
+	ErrNoRenderer = errors.New("no renderer")
+	ErrDisplay    = errors.New("could not open display")
+	ErrTexture    = errors.New("could not create texture")
+	ErrText       = errors.New("could not render text")
+)
+
+//An error from a Backend, holding one of the Err kinds along with the Backend's own message.
+type Error struct {
+	//One of the Err kinds, which this Error unwraps to.
+	Kind error
+	//The operation that failed, e.g. "LoadImage".
+	Op string
+	//The file involved, if any.
+	Path string
+	//The Backend's description of the problem, e.g. from SDL_GetError.
+	Msg string
+}
+
+func (me *Error) Error() string {
+	str := me.Op
+	if me.Path != "" {
+		str += " " + me.Path
+	}
+	str += ": " + me.Kind.Error()
+	if me.Msg != "" {
+		str += ": " + me.Msg
+	}
+	return str
+}
+
+func (me *Error) Unwrap() error {
+	return me.Kind
+}
@@ -0,0 +1,665 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"sync"
+)
+
+//The display size used when OpenDisplay is given a size of 0.
+const headlessDefaultWidth, headlessDefaultHeight = 800, 600
+
+//A pure Go Backend that draws into an in-memory image.RGBA instead of a window.
+//It needs neither SDL nor a display, making it suitable for tests and servers.
+//
+//Text is drawn as a solid box per glyph, as there is no font rasterizer to draw
+//real glyphs with.
+type Headless struct {
+	lock       sync.Mutex
+	back       *image.RGBA
+	front      *image.RGBA
+	dst        *image.RGBA //the image currently being drawn into
+	clip       image.Rectangle
+	paint      image.Image
+	mode       int
+	imageColor Color
+	closed     chan interface{}
+	events     []interface{} //posted by HandleEvents, so nothing blocks on a full input queue
+	wake       chan interface{}
+}
+
+//Returns a new Headless Backend.
+func NewHeadless() *Headless {
+	me := new(Headless)
+	me.closed = make(chan interface{})
+	me.wake = make(chan interface{}, 1)
+	me.imageColor = Color{255, 255, 255, 255}
+	return me
+}
+
+//Returns a copy of the last frame presented by Draw.
+//Returns nil if no frame has been presented yet.
+func (me *Headless) Frame() *image.RGBA {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if me.front == nil {
+		return nil
+	}
+	f := image.NewRGBA(me.front.Bounds())
+	copy(f.Pix, me.front.Pix)
+	return f
+}
+
+//Opens a display of the given size. Options other than the size have no effect.
+func (me *Headless) OpenDisplay(opts DisplayOptions) error {
+	w, h := opts.Width, opts.Height
+	//there is no screen to take the size of
+	if w <= 0 {
+		w = headlessDefaultWidth
+	}
+	if h <= 0 {
+		h = headlessDefaultHeight
+	}
+	me.lock.Lock()
+	me.back = image.NewRGBA(image.Rect(0, 0, w, h))
+	me.dst = me.back
+	me.clip = me.back.Bounds()
+	me.lock.Unlock()
+	return nil
+}
+
+//Changes the size of the display, as if the window had been resized, and queues a WindowEvent
+//for HandleEvents to post. Must not be called while drawing.
+func (me *Headless) Resize(w, h int) {
+	me.lock.Lock()
+	me.back = image.NewRGBA(image.Rect(0, 0, w, h))
+	me.dst = me.back
+	me.clip = me.back.Bounds()
+	me.events = append(me.events, WindowEvent{Type: Window_Resized, Width: w, Height: h})
+	me.lock.Unlock()
+	select {
+	case me.wake <- nil:
+	default:
+	}
+}
+
+func (me *Headless) CloseDisplay() {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	select {
+	case <-me.closed:
+	default:
+		close(me.closed)
+	}
+}
+
+func (me *Headless) SetDisplayTitle(title string) {
+}
+
+func (me *Headless) SetDisplayIcon(icon image.Image) error {
+	return nil
+}
+
+func (me *Headless) DisplaySize() (int, int) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if me.back == nil {
+		return 0, 0
+	}
+	s := me.back.Bounds().Size()
+	return s.X, s.Y
+}
+
+//Runs f, then copies the drawn frame to the one returned by Frame.
+func (me *Headless) Draw(f func()) {
+	if me.back == nil {
+		return
+	}
+	f()
+	me.lock.Lock()
+	if me.front == nil || me.front.Bounds() != me.back.Bounds() {
+		me.front = image.NewRGBA(me.back.Bounds())
+	}
+	copy(me.front.Pix, me.back.Pix)
+	me.lock.Unlock()
+}
+
+func (me *Headless) DrawTo(target *Image, f func()) {
+	t := rgba(target)
+	if t == nil {
+		return
+	}
+	dst, clip, mode := me.dst, me.clip, me.mode
+	me.dst, me.clip = t, t.Bounds()
+	f()
+	me.dst, me.clip, me.mode = dst, clip, mode
+}
+
+func (me *Headless) Clear(c Color) {
+	draw.Draw(me.dst, me.dst.Bounds(), image.NewUniform(c.toNRGBA()), image.Point{}, draw.Src)
+}
+
+func (me *Headless) ReadPixels(dst *image.RGBA) (*image.RGBA, error) {
+	if dst == nil || dst.Rect != me.dst.Bounds() {
+		dst = image.NewRGBA(me.dst.Bounds())
+	}
+	copy(dst.Pix, me.dst.Pix)
+	return dst, nil
+}
+
+//IMAGE HANDLING
+
+func rgba(img *Image) *image.RGBA {
+	t, _ := img.Texture.(*image.RGBA)
+	return t
+}
+
+func (me *Headless) TextureSize(img *Image) (int, int) {
+	t := rgba(img)
+	if t == nil {
+		return 0, 0
+	}
+	s := t.Bounds().Size()
+	return s.X, s.Y
+}
+
+func (me *Headless) LoadImage(path string) (*Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &Error{Kind: ErrNotFound, Op: "LoadImage", Path: path, Msg: err.Error()}
+	}
+	defer file.Close()
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, &Error{Kind: ErrDecode, Op: "LoadImage", Path: path, Msg: err.Error()}
+	}
+	t := image.NewRGBA(image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy()))
+	draw.Draw(t, t.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	retval := new(Image)
+	retval.Texture = t
+	retval.Width = t.Bounds().Dx()
+	retval.Height = t.Bounds().Dy()
+	return retval, nil
+}
+
+func (me *Headless) CreateTarget(w, h int) (*Image, error) {
+	if w <= 0 || h <= 0 {
+		return nil, &Error{Kind: ErrTexture, Op: "CreateTarget", Msg: "size must be positive"}
+	}
+	retval := new(Image)
+	retval.Texture = image.NewRGBA(image.Rect(0, 0, w, h))
+	retval.Width = w
+	retval.Height = h
+	return retval, nil
+}
+
+func (me *Headless) FreeImage(img *Image) {
+	img.Texture = nil
+}
+
+func (me *Headless) ResizeAngleOf(img *Image, angle float64, width, height, filter int) (*Image, error) {
+	if img.W() == 0 || img.H() == 0 {
+		return nil, &Error{Kind: ErrTexture, Op: "ResizeAngleOf", Msg: "image is empty"}
+	}
+	if width <= 0 || height <= 0 {
+		return nil, &Error{Kind: ErrTexture, Op: "ResizeAngleOf", Msg: "size must be positive"}
+	}
+	t := resample(rgba(img), angle, width, height, filter)
+	retval := new(Image)
+	retval.Texture = t
+	retval.Width = t.Bounds().Dx()
+	retval.Height = t.Bounds().Dy()
+	return retval, nil
+}
+
+//TEXT HANDLING
+
+//The size a Headless font was loaded at.
+type headlessFace struct {
+	size int
+}
+
+func (me *Headless) LoadFont(path string, size int) (*Font, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, &Error{Kind: ErrNotFound, Op: "LoadFont", Path: path, Msg: err.Error()}
+	}
+	font := new(Font)
+	font.Face = &headlessFace{size: size}
+	return font, nil
+}
+
+func (me *Headless) FreeFont(val *Font) {
+	val.Face = nil
+}
+
+//Writes the text as one box per glyph, size/2 wide and sitting on the baseline.
+func (me *Headless) WriteText(font *Font, text string, t *Image, c Color) error {
+	face, _ := font.Face.(*headlessFace)
+	if face == nil || face.size < 2 {
+		return &Error{Kind: ErrText, Op: "WriteText", Msg: "font is too small or was freed"}
+	}
+	advance := face.size / 2
+	glyphs := []rune(text)
+	if len(glyphs) == 0 {
+		return &Error{Kind: ErrText, Op: "WriteText", Msg: "text is empty"}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, advance*len(glyphs), face.size))
+	top := face.size / 4
+	bottom := face.size - face.size/8
+	for i, g := range glyphs {
+		if g == ' ' {
+			continue
+		}
+		r := image.Rect(i*advance, top, (i+1)*advance-1, bottom)
+		draw.Draw(img, r, image.NewUniform(c.toNRGBA()), image.Point{}, draw.Src)
+	}
+	t.Texture = img
+	t.Width = img.Bounds().Dx()
+	t.Height = img.Bounds().Dy()
+	return nil
+}
+
+//GFX HANDLING
+
+//Limits drawing to the given bounds. A negative width or height means the whole display.
+func (me *Headless) SetClipRect(x, y, w, h int) {
+	if w < 0 || h < 0 {
+		me.clip = me.dst.Bounds()
+		return
+	}
+	me.clip = image.Rect(x, y, x+w, y+h).Intersect(me.dst.Bounds())
+}
+
+//Makes fills use the colors of the given paint instead of their own, until it is set back to nil.
+func (me *Headless) SetPaint(paint image.Image) {
+	me.paint = paint
+}
+
+//Makes everything drawn after blend into what is under it by the given mode,
+//by the same formulas SDL uses for the mode.
+func (me *Headless) SetBlendMode(mode int) {
+	if mode < BlendMode_Alpha || mode > BlendMode_None {
+		mode = BlendMode_Alpha
+	}
+	me.mode = mode
+}
+
+func (me *Headless) SetImageColor(c Color) {
+	me.imageColor = c
+}
+
+//Returns the image with its colors multiplied by the image color, if it is not opaque white.
+func (me *Headless) modulate(img image.Image) image.Image {
+	if me.imageColor == (Color{255, 255, 255, 255}) {
+		return img
+	}
+	return &modulatedImage{img, me.imageColor}
+}
+
+//Fills the rectangle from (x, y) to (x+w, y+h) inclusive, like SDL_gfx's roundedBoxRGBA.
+func (me *Headless) FillRoundedRect(x, y, w, h, radius int, c Color) {
+	me.fill(&roundedRectMask{image.Rect(x, y, x+w+1, y+h+1), radius}, c)
+}
+
+func (me *Headless) DrawRoundedRect(x, y, w, h, radius, width int, c Color) {
+	me.fillMask(newRoundedRectRing(image.Rect(x, y, x+w+1, y+h+1), radius, width), c)
+}
+
+func (me *Headless) FillRect(x, y, w, h int, c Color) {
+	if me.paint != nil {
+		me.fill(image.Rect(x, y, x+w, y+h), c)
+		return
+	}
+	r := image.Rect(x, y, x+w, y+h).Intersect(me.clip)
+	me.composite(r, image.NewUniform(c.toNRGBA()), image.Point{}, nil)
+}
+
+//Draws the line through pixel centers, as a polygon width pixels wide for thick or antialiased lines
+//like SDL_gfx's thickLineRGBA.
+func (me *Headless) DrawLine(x1, y1, x2, y2, width int, c Color, antialias bool) {
+	if width <= 1 && !antialias {
+		me.fillMask(lineMask(x1, y1, x2, y2, me.clip), c)
+		return
+	}
+	if width < 1 {
+		width = 1
+	}
+	me.fillMask(thickLineMask(x1, y1, x2, y2, width, antialias, me.clip), c)
+}
+
+func (me *Headless) FillEllipse(x, y, rx, ry int, c Color, antialias bool) {
+	me.fill(filledEllipseMask(x, y, rx, ry, antialias), c)
+}
+
+func (me *Headless) DrawEllipse(x, y, rx, ry int, c Color, antialias bool) {
+	me.fillMask(ellipseMask(x, y, rx, ry, antialias), c)
+}
+
+func (me *Headless) DrawArc(x, y, radius, start, end int, c Color, antialias bool) {
+	me.fillMask(arcMask(x, y, radius, start, end, antialias), c)
+}
+
+func (me *Headless) FillPie(x, y, radius, start, end int, c Color, antialias bool) {
+	me.fill(pieMask(x, y, radius, start, end, antialias), c)
+}
+
+func (me *Headless) FillPolygon(points []image.Point, rule int, c Color, antialias bool) {
+	me.fill(polygonMask(pixelCenters(points), rule, antialias, me.clip), c)
+}
+
+func (me *Headless) FillPath(contours [][]PointF, rule int, c Color, antialias bool) {
+	me.fill(pathMask(contours, rule, antialias, me.clip), c)
+}
+
+//Fills the opaque parts of the mask with the paint, if one is set, or else with the given color.
+func (me *Headless) fill(mask image.Image, c Color) {
+	if me.paint == nil {
+		me.fillMask(mask, c)
+		return
+	}
+	r := mask.Bounds().Intersect(me.clip)
+	me.composite(r, me.paint, r.Min, mask)
+}
+
+//Fills the opaque parts of the mask with the given color.
+func (me *Headless) fillMask(mask image.Image, c Color) {
+	r := mask.Bounds().Intersect(me.clip)
+	me.composite(r, image.NewUniform(c.toNRGBA()), image.Point{}, mask)
+}
+
+//Blends the source, seen through the mask if there is one, into the rectangle of the destination
+//by the blend mode. The mask is in destination coordinates.
+func (me *Headless) composite(r image.Rectangle, src image.Image, sp image.Point, mask image.Image) {
+	if me.mode == BlendMode_Alpha {
+		draw.DrawMask(me.dst, r, src, sp, mask, r.Min, draw.Over)
+		return
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cover := 1.0
+			if mask != nil {
+				_, _, _, m := mask.At(x, y).RGBA()
+				if m == 0 {
+					continue
+				}
+				cover = float64(m) / 0xffff
+			}
+			sr, sg, sb, sa := src.At(sp.X+x-r.Min.X, sp.Y+y-r.Min.Y).RGBA()
+			me.dst.SetRGBA(x, y, blend(me.mode, me.dst.RGBAAt(x, y), sr, sg, sb, sa, cover))
+		}
+	}
+}
+
+//Returns the destination pixel after blending the premultiplied source color into it by the mode,
+//with the source covering the given share of the pixel.
+func blend(mode int, d color.RGBA, sr, sg, sb, sa uint32, cover float64) color.RGBA {
+	a := float64(sa) / 0xffff * cover
+	da := float64(d.A) / 255
+	if mode == BlendMode_None {
+		da = a
+	}
+	src := [3]uint32{sr, sg, sb}
+	dst := [3]float64{float64(d.R) / 255, float64(d.G) / 255, float64(d.B) / 255}
+	for i := range dst {
+		//the source's color before its alpha was applied
+		c := 0.0
+		if sa != 0 {
+			c = float64(src[i]) / float64(sa)
+		}
+		switch mode {
+		case BlendMode_Add:
+			dst[i] += c * a
+		case BlendMode_Modulate:
+			dst[i] *= 1 - cover + c*cover
+		case BlendMode_Multiply:
+			dst[i] = c*a*dst[i] + dst[i]*(1-a)
+		case BlendMode_Screen:
+			dst[i] = c*a + dst[i] - c*a*dst[i]
+		case BlendMode_None:
+			dst[i] = c * a
+		}
+		//keep the color premultiplied by the alpha
+		dst[i] = math.Min(dst[i], da)
+	}
+	return color.RGBA{uint8(math.Round(dst[0] * 255)), uint8(math.Round(dst[1] * 255)), uint8(math.Round(dst[2] * 255)), uint8(math.Round(da * 255))}
+}
+
+//Draws the image at the given coordinates, scaling the source rect to the Image's size.
+func (me *Headless) DrawImage(img *Image, destX, destY, srcX, srcY, srcW, srcH int) {
+	t := rgba(img)
+	if t == nil {
+		return
+	}
+	src := image.Rect(srcX, srcY, srcX+srcW, srcY+srcH).Intersect(t.Bounds())
+	dest := image.Rect(destX, destY, destX+img.Width, destY+img.Height)
+	if src.Empty() || dest.Empty() {
+		return
+	}
+	r := dest.Intersect(me.clip)
+	me.composite(r, me.modulate(&scaledImage{t, src, dest}), r.Min, nil)
+}
+
+//Draws the source rect of the image onto the parallelogram made by the first three corners of the quad,
+//taking each pixel from the source pixel under its center.
+func (me *Headless) DrawImageQuad(img *Image, srcX, srcY, srcW, srcH int, quad [4]PointF) {
+	t := rgba(img)
+	if t == nil || srcW <= 0 || srcH <= 0 {
+		return
+	}
+	src := image.Rect(srcX, srcY, srcX+srcW, srcY+srcH)
+	u := PointF{(quad[1].X - quad[0].X) / float64(srcW), (quad[1].Y - quad[0].Y) / float64(srcW)}
+	v := PointF{(quad[2].X - quad[1].X) / float64(srcH), (quad[2].Y - quad[1].Y) / float64(srcH)}
+	det := u.X*v.Y - u.Y*v.X
+	if det == 0 {
+		return
+	}
+	r := contourBounds([][]PointF{quad[:]}).Intersect(me.clip)
+	me.composite(r, me.modulate(&quadImage{t, src, quad[0], u, v, det}), r.Min, nil)
+}
+
+//Draws the image as a quad, like SDL_RenderCopyEx.
+func (me *Headless) DrawImageEx(img *Image, srcX, srcY, srcW, srcH, destX, destY, destW, destH, flip int, angle float64, pivotX, pivotY int) {
+	me.DrawImageQuad(img, srcX, srcY, srcW, srcH, ImageExQuad(destX, destY, destW, destH, flip, angle, pivotX, pivotY))
+}
+
+//EVENT HANDLING
+
+//Posts the events queued by Resize until CloseDisplay is called.
+func (me *Headless) HandleEvents() {
+	for {
+		select {
+		case <-me.closed:
+			return
+		case <-me.wake:
+			me.lock.Lock()
+			events := me.events
+			me.events = nil
+			me.lock.Unlock()
+			for _, e := range events {
+				PostEvent(e)
+			}
+		}
+	}
+}
+
+//MISC
+
+func (me *Color) toNRGBA() color.NRGBA {
+	return color.NRGBA{me.Red, me.Green, me.Blue, me.Alpha}
+}
+
+//An alpha mask of a rectangle with corners rounded to the given radius.
+type roundedRectMask struct {
+	rect   image.Rectangle
+	radius int
+}
+
+func (me *roundedRectMask) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (me *roundedRectMask) Bounds() image.Rectangle {
+	return me.rect
+}
+
+func (me *roundedRectMask) At(x, y int) color.Color {
+	if !(image.Point{x, y}).In(me.rect) {
+		return color.Transparent
+	}
+	//keep the corners from overlapping
+	rad := me.radius
+	if limit := (me.rect.Dx() - 1) / 2; rad > limit {
+		rad = limit
+	}
+	if limit := (me.rect.Dy() - 1) / 2; rad > limit {
+		rad = limit
+	}
+	//find the center of the nearest corner's circle, if in a corner
+	cx, cy := x, y
+	if x < me.rect.Min.X+rad {
+		cx = me.rect.Min.X + rad
+	} else if x > me.rect.Max.X-1-rad {
+		cx = me.rect.Max.X - 1 - rad
+	}
+	if y < me.rect.Min.Y+rad {
+		cy = me.rect.Min.Y + rad
+	} else if y > me.rect.Max.Y-1-rad {
+		cy = me.rect.Max.Y - 1 - rad
+	}
+	dx, dy := x-cx, y-cy
+	if dx*dx+dy*dy > rad*rad {
+		return color.Transparent
+	}
+	return color.Opaque
+}
+
+//An alpha mask of the border of a rounded rectangle, the given width in pixels inward from its edges.
+type roundedRectRing struct {
+	outer, inner roundedRectMask
+}
+
+func newRoundedRectRing(rect image.Rectangle, radius, width int) *roundedRectRing {
+	inner := radius - width
+	if inner < 0 {
+		inner = 0
+	}
+	return &roundedRectRing{roundedRectMask{rect, radius}, roundedRectMask{rect.Inset(width), inner}}
+}
+
+func (me *roundedRectRing) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (me *roundedRectRing) Bounds() image.Rectangle {
+	return me.outer.rect
+}
+
+func (me *roundedRectRing) At(x, y int) color.Color {
+	if me.inner.rect.Empty() {
+		return me.outer.At(x, y)
+	}
+	if _, _, _, a := me.inner.At(x, y).RGBA(); a != 0 {
+		return color.Transparent
+	}
+	return me.outer.At(x, y)
+}
+
+//Maps the dest rectangle onto the src rectangle of an image, sampling the nearest pixel.
+type scaledImage struct {
+	img       *image.RGBA
+	src, dest image.Rectangle
+}
+
+func (me *scaledImage) ColorModel() color.Model {
+	return me.img.ColorModel()
+}
+
+func (me *scaledImage) Bounds() image.Rectangle {
+	return me.dest
+}
+
+func (me *scaledImage) At(x, y int) color.Color {
+	if !(image.Point{x, y}).In(me.dest) {
+		return color.Transparent
+	}
+	sx := me.src.Min.X + (x-me.dest.Min.X)*me.src.Dx()/me.dest.Dx()
+	sy := me.src.Min.Y + (y-me.dest.Min.Y)*me.src.Dy()/me.dest.Dy()
+	return me.img.At(sx, sy)
+}
+
+//An image of the source rect of another image, mapped so that stepping a pixel across or down the
+//source steps u or v across the display.
+type quadImage struct {
+	img    *image.RGBA
+	src    image.Rectangle
+	origin PointF
+	u, v   PointF
+	det    float64
+}
+
+func (me *quadImage) ColorModel() color.Model {
+	return me.img.ColorModel()
+}
+
+func (me *quadImage) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (me *quadImage) At(x, y int) color.Color {
+	dx, dy := float64(x)+0.5-me.origin.X, float64(y)+0.5-me.origin.Y
+	sx := (dx*me.v.Y - dy*me.v.X) / me.det
+	sy := (dy*me.u.X - dx*me.u.Y) / me.det
+	pt := image.Point{me.src.Min.X + int(math.Floor(sx)), me.src.Min.Y + int(math.Floor(sy))}
+	if !pt.In(me.src) {
+		return color.Transparent
+	}
+	return me.img.At(pt.X, pt.Y)
+}
+
+//An image with its colors and alpha multiplied by a color.
+type modulatedImage struct {
+	img image.Image
+	mod Color
+}
+
+func (me *modulatedImage) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (me *modulatedImage) Bounds() image.Rectangle {
+	return me.img.Bounds()
+}
+
+func (me *modulatedImage) At(x, y int) color.Color {
+	r, g, b, a := me.img.At(x, y).RGBA()
+	//the colors are premultiplied, so they are faded by the alpha too
+	ma := uint32(me.mod.Alpha)
+	return color.RGBA64{
+		uint16(r * uint32(me.mod.Red) / 255 * ma / 255),
+		uint16(g * uint32(me.mod.Green) / 255 * ma / 255),
+		uint16(b * uint32(me.mod.Blue) / 255 * ma / 255),
+		uint16(a * ma / 255),
+	}
+}
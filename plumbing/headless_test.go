@@ -0,0 +1,542 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHeadlessFillRect(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 20, Height: 20})
+	h.Draw(func() {
+		h.FillRect(0, 0, 20, 20, Color{0, 0, 0, 255})
+		h.SetClipRect(5, 5, 10, 10)
+		h.FillRect(0, 0, 10, 10, Color{255, 0, 0, 255})
+	})
+	f := h.Frame()
+	if f.RGBAAt(4, 4) != (color.RGBA{0, 0, 0, 255}) {
+		t.Error("Headless.FillRect draws outside of the clip rect.")
+	}
+	if f.RGBAAt(5, 5) != (color.RGBA{255, 0, 0, 255}) || f.RGBAAt(9, 9) != (color.RGBA{255, 0, 0, 255}) {
+		t.Error("Headless.FillRect does not fill inside of the clip rect.")
+	}
+	if f.RGBAAt(10, 10) != (color.RGBA{0, 0, 0, 255}) {
+		t.Error("Headless.FillRect fills beyond its width and height.")
+	}
+}
+
+func TestHeadlessClipRectFromTop(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 20, Height: 20})
+	h.Draw(func() {
+		h.SetClipRect(2, 6, 4, 3)
+		h.FillRect(0, 0, 20, 20, Color{255, 0, 0, 255})
+	})
+	f := h.Frame()
+	if f.RGBAAt(2, 6).A == 0 || f.RGBAAt(5, 8).A == 0 {
+		t.Error("Headless.SetClipRect does not measure y down from the top.")
+	}
+	if f.RGBAAt(2, 5).A != 0 || f.RGBAAt(2, 9).A != 0 || f.RGBAAt(6, 6).A != 0 {
+		t.Error("Headless.SetClipRect lets fills outside of it.")
+	}
+}
+
+func TestHeadlessOpenDisplayDefaultSize(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Height: 300})
+	if w, ht := h.DisplaySize(); w != headlessDefaultWidth || ht != 300 {
+		t.Errorf("Opening a display with a width of 0 made it %dx%d.", w, ht)
+	}
+}
+
+func TestHeadlessResizeQueuesEvents(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 20, Height: 20})
+	//more resizes than the input queue holds, with nothing handling input
+	for i := 1; i <= cap(inputChan)*2; i++ {
+		h.Resize(i, i)
+	}
+	go h.HandleEvents()
+	defer h.CloseDisplay()
+	for {
+		select {
+		case e := <-inputChan:
+			if e, ok := e.(WindowEvent); ok && e.Width == cap(inputChan)*2 {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("HandleEvents did not post the queued resizes.")
+		}
+	}
+}
+
+func TestHeadlessFillRoundedRect(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 20, Height: 20})
+	h.Draw(func() {
+		h.FillRoundedRect(0, 0, 10, 10, 4, Color{0, 255, 0, 255})
+	})
+	f := h.Frame()
+	if f.RGBAAt(0, 0).A != 0 {
+		t.Error("Headless.FillRoundedRect does not round its corners.")
+	}
+	if f.RGBAAt(5, 5) != (color.RGBA{0, 255, 0, 255}) || f.RGBAAt(10, 5).A == 0 {
+		t.Error("Headless.FillRoundedRect does not fill its body.")
+	}
+}
+
+func TestHeadlessDrawRoundedRect(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 20, Height: 20})
+	h.Draw(func() {
+		h.DrawRoundedRect(0, 0, 10, 10, 4, 2, Color{0, 255, 0, 255})
+	})
+	f := h.Frame()
+	if f.RGBAAt(0, 0).A != 0 {
+		t.Error("Headless.DrawRoundedRect does not round its corners.")
+	}
+	if f.RGBAAt(5, 0).A == 0 || f.RGBAAt(5, 1).A == 0 || f.RGBAAt(10, 5).A == 0 || f.RGBAAt(9, 5).A == 0 {
+		t.Error("Headless.DrawRoundedRect does not draw its border the given width.")
+	}
+	if f.RGBAAt(5, 2).A != 0 || f.RGBAAt(5, 5).A != 0 {
+		t.Error("Headless.DrawRoundedRect fills inside of its border.")
+	}
+}
+
+func TestHeadlessBlendModes(t *testing.T) {
+	tests := []struct {
+		mode int
+		want color.RGBA
+	}{
+		{BlendMode_Alpha, color.RGBA{200, 49, 24, 255}},
+		{BlendMode_Add, color.RGBA{255, 100, 50, 255}},
+		{BlendMode_Modulate, color.RGBA{157, 0, 0, 255}},
+		{BlendMode_Multiply, color.RGBA{178, 50, 25, 255}},
+		{BlendMode_Screen, color.RGBA{222, 100, 50, 255}},
+		{BlendMode_None, color.RGBA{100, 0, 0, 128}},
+	}
+	for _, test := range tests {
+		h := NewHeadless()
+		h.OpenDisplay(DisplayOptions{Width: 4, Height: 4})
+		h.Draw(func() {
+			h.Clear(Color{200, 100, 50, 255})
+			h.SetBlendMode(test.mode)
+			h.FillRect(0, 0, 2, 2, Color{200, 0, 0, 128})
+		})
+		f := h.Frame()
+		if got := f.RGBAAt(0, 0); !near(got, test.want) {
+			t.Errorf("Blend mode %d gives %v, expected %v", test.mode, got, test.want)
+		}
+		if got := f.RGBAAt(3, 3); got != (color.RGBA{200, 100, 50, 255}) {
+			t.Errorf("Blend mode %d changes pixels outside of the rect to %v", test.mode, got)
+		}
+	}
+}
+
+//Reports whether the colors are within rounding of each other.
+func near(a, b color.RGBA) bool {
+	d := func(x, y uint8) bool {
+		return abs(int(x)-int(y)) < 3
+	}
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B) && d(a.A, b.A)
+}
+
+func TestHeadlessDrawLine(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 20, Height: 20})
+	white := color.RGBA{255, 255, 255, 255}
+	h.Draw(func() {
+		h.DrawLine(2, 2, 12, 7, 1, Color{255, 255, 255, 255}, false)
+		h.DrawLine(2, 15, 17, 15, 4, Color{255, 255, 255, 255}, false)
+	})
+	f := h.Frame()
+	if f.RGBAAt(2, 2) != white || f.RGBAAt(12, 7) != white || f.RGBAAt(7, 4).A == 0 && f.RGBAAt(7, 5).A == 0 {
+		t.Error("Headless.DrawLine does not draw from end to end.")
+	}
+	if f.RGBAAt(12, 2).A != 0 || f.RGBAAt(13, 7).A != 0 {
+		t.Error("Headless.DrawLine draws off of the line.")
+	}
+	for x := 2; x <= 12; x++ {
+		n := 0
+		for y := 0; y < 10; y++ {
+			if f.RGBAAt(x, y).A != 0 {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("Headless.DrawLine draws %d pixels in column %d of a line wider than it is tall, expected 1.", n, x)
+		}
+	}
+	if f.RGBAAt(2, 15) != white || f.RGBAAt(17, 15) != white || f.RGBAAt(1, 15).A != 0 || f.RGBAAt(18, 15).A != 0 {
+		t.Error("Thick line does not reach exactly the pixels at its ends.")
+	}
+	for y := 13; y <= 16; y++ {
+		if f.RGBAAt(10, y) != white {
+			t.Errorf("Thick line does not cover (10, %d).", y)
+		}
+	}
+	if f.RGBAAt(10, 12).A != 0 || f.RGBAAt(10, 17).A != 0 {
+		t.Error("Thick line is wider than its width.")
+	}
+}
+
+func TestHeadlessOffscreenShapes(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 100, Height: 100})
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	h.Draw(func() {
+		h.DrawLine(0, 0, 20000, 20000, 3, Color{255, 255, 255, 255}, false)
+		h.DrawLine(-20000, 20, 20000, 30, 1, Color{0, 0, 255, 255}, false)
+		h.FillPolygon([]image.Point{{-20000, 90}, {20000, 90}, {20000, 20000}, {-20000, 20000}}, FillRule_NonZero, Color{255, 0, 0, 255}, true)
+		h.FillPath([][]PointF{{{20000, -20000}, {20100, -20000}, {20100, -19900}}}, FillRule_NonZero, Color{0, 255, 0, 255}, true)
+	})
+	runtime.ReadMemStats(&after)
+	//the shapes are only masked where they are on the display, which is 10000 pixels
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("Drawing shapes reaching far off of the display allocated %d bytes.", n)
+	}
+	f := h.Frame()
+	if f.RGBAAt(50, 50) != (color.RGBA{255, 255, 255, 255}) || f.RGBAAt(50, 10).A != 0 {
+		t.Error("A thick line reaching far off of the display is not drawn on it.")
+	}
+	if f.RGBAAt(10, 95) != (color.RGBA{255, 0, 0, 255}) || f.RGBAAt(10, 85).A != 0 {
+		t.Error("A polygon reaching far off of the display is not drawn on it.")
+	}
+	for x := 0; x < 100; x++ {
+		if f.RGBAAt(x, 25) != (color.RGBA{0, 0, 255, 255}) {
+			t.Fatalf("A line reaching far off of the display is not drawn in column %d.", x)
+		}
+	}
+}
+
+func TestLineMaskClipped(t *testing.T) {
+	//the pixels of the whole line, stepping along it one pixel at a time
+	line := func(x1, y1, x2, y2 int) map[image.Point]bool {
+		pts := map[image.Point]bool{}
+		dx, dy := abs(x2-x1), -abs(y2-y1)
+		sx, sy := 1, 1
+		if x2 < x1 {
+			sx = -1
+		}
+		if y2 < y1 {
+			sy = -1
+		}
+		err := dx + dy
+		for x, y := x1, y1; ; {
+			pts[image.Point{x, y}] = true
+			if x == x2 && y == y2 {
+				return pts
+			}
+			e2 := 2 * err
+			if e2 >= dy {
+				err += dy
+				x += sx
+			}
+			if e2 <= dx {
+				err += dx
+				y += sy
+			}
+		}
+	}
+	lines := [][4]int{{2, 3, 40, 17}, {40, 17, 2, 3}, {5, 38, 9, 1}, {30, 2, 3, 29}, {-7, 12, 45, 14}, {20, -9, 21, 50}, {4, 4, 4, 4}, {1, 20, 39, 20}}
+	for _, clip := range []image.Rectangle{image.Rect(-10, -10, 50, 60), image.Rect(8, 6, 27, 19), image.Rect(100, 100, 110, 110)} {
+		for _, l := range lines {
+			want := line(l[0], l[1], l[2], l[3])
+			mask := lineMask(l[0], l[1], l[2], l[3], clip)
+			for y := clip.Min.Y; y < clip.Max.Y; y++ {
+				for x := clip.Min.X; x < clip.Max.X; x++ {
+					if got := mask.AlphaAt(x, y).A != 0; got != want[image.Point{x, y}] {
+						t.Fatalf("The line %v clipped to %v is %v at (%d, %d), expected %v.", l, clip, got, x, y, !got)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHeadlessDrawImage(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 20, Height: 20})
+	font, err := h.LoadFont("main_test.go", 8)
+	if err != nil {
+		t.Fatal("Headless.LoadFont failed:", err)
+	}
+	var text Image
+	if err := h.WriteText(font, "ab", &text, Color{0, 0, 255, 255}); err != nil {
+		t.Fatal("Headless.WriteText failed:", err)
+	}
+	text.Width *= 2
+	text.Height *= 2
+	h.Draw(func() {
+		h.DrawImage(&text, 2, 2, 0, 0, 8, 8)
+	})
+	f := h.Frame()
+	if f.RGBAAt(2, 2).A != 0 {
+		t.Error("Headless.DrawImage draws transparent pixels.")
+	}
+	//the first glyph box covers (0, 2) through (2, 6), doubled and offset by 2
+	if f.RGBAAt(2, 6) != (color.RGBA{0, 0, 255, 255}) || f.RGBAAt(7, 15) != (color.RGBA{0, 0, 255, 255}) {
+		t.Error("Headless.DrawImage does not scale the image to its size.")
+	}
+}
+
+func TestHeadlessResizeAngleOf(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 8, Height: 8})
+	pix := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	pix.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	pix.SetRGBA(1, 0, color.RGBA{0, 0, 255, 255})
+	src := &Image{Texture: pix, Width: 2, Height: 1}
+
+	turned, err := h.ResizeAngleOf(src, 90, 2, 1, Filter_Nearest)
+	if err != nil {
+		t.Fatal("Headless.ResizeAngleOf failed:", err)
+	}
+	if turned.W() != 1 || turned.H() != 2 {
+		t.Errorf("Rotating a 2x1 image by 90 degrees made it %dx%d.", turned.W(), turned.H())
+	}
+	if rgba(turned).RGBAAt(0, 0) != (color.RGBA{255, 0, 0, 255}) || rgba(turned).RGBAAt(0, 1) != (color.RGBA{0, 0, 255, 255}) {
+		t.Error("Headless.ResizeAngleOf does not rotate clockwise.")
+	}
+
+	scaled, err := h.ResizeAngleOf(src, 0, 4, 2, Filter_Bilinear)
+	if err != nil {
+		t.Fatal("Headless.ResizeAngleOf failed:", err)
+	}
+	h.FreeImage(src)
+	if rgba(scaled) == nil || scaled.W() != 4 || scaled.H() != 2 {
+		t.Fatal("Freeing the source of a resized image freed the resized image.")
+	}
+	if rgba(scaled).RGBAAt(0, 0) != (color.RGBA{255, 0, 0, 255}) || rgba(scaled).RGBAAt(3, 1) != (color.RGBA{0, 0, 255, 255}) {
+		t.Error("Headless.ResizeAngleOf with Filter_Bilinear changes the colors at the edges of an unrotated image.")
+	}
+	if c := rgba(scaled).RGBAAt(1, 0); c.R <= c.B || c.B == 0 || c.A != 255 {
+		t.Errorf("Headless.ResizeAngleOf with Filter_Bilinear does not blend neighbouring pixels, got %v.", c)
+	}
+
+	solid := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range solid.Pix {
+		solid.Pix[i] = 255
+	}
+	turned, err = h.ResizeAngleOf(&Image{Texture: solid, Width: 4, Height: 4}, 45, 4, 4, Filter_Bicubic)
+	if err != nil {
+		t.Fatal("Headless.ResizeAngleOf failed:", err)
+	}
+	if turned.W() != 6 || turned.H() != 6 {
+		t.Errorf("Rotating a 4x4 image by 45 degrees made it %dx%d.", turned.W(), turned.H())
+	}
+	if rgba(turned).RGBAAt(0, 0).A != 0 || rgba(turned).RGBAAt(3, 3) != (color.RGBA{255, 255, 255, 255}) {
+		t.Error("Headless.ResizeAngleOf does not leave the corners of a rotated image transparent.")
+	}
+	if _, err := h.ResizeAngleOf(&Image{Texture: solid, Width: 4, Height: 4}, 0, 0, 4, Filter_Nearest); err == nil {
+		t.Error("Headless.ResizeAngleOf accepts an empty size.")
+	}
+}
+
+//Waits for n Screenshots to be waiting for the next frame.
+func waitForFrameRequests(t *testing.T, n int) {
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		frameHookLock.Lock()
+		waiting := len(frameRequests)
+		frameHookLock.Unlock()
+		if waiting == n {
+			return
+		}
+		if time.Since(start) > time.Second {
+			t.Fatalf("%d Screenshots are waiting for a frame, expected %d.", waiting, n)
+		}
+	}
+}
+
+func TestScreenshot(t *testing.T) {
+	h := NewHeadless()
+	SetBackend(h)
+	OpenDisplay(10, 10, false)
+	framePix = nil
+	SetDrawFunc(func() {
+		FillRect(0, 0, 10, 10, Color{0, 0, 255, 255})
+	})
+	defer SetDrawFunc(func() {})
+	Draw()
+	if framePix != nil {
+		t.Error("Draw reads the frame back when nothing wants it.")
+	}
+
+	shot := make(chan *image.RGBA)
+	go func() {
+		img, err := Screenshot()
+		if err != nil {
+			t.Error("Screenshot failed:", err)
+		}
+		shot <- img
+	}()
+	waitForFrameRequests(t, 1)
+	Draw()
+	img := <-shot
+	SetDrawFunc(func() {
+		FillRect(0, 0, 10, 10, Color{255, 0, 0, 255})
+	})
+	var hooked []*image.RGBA
+	SetFrameHook(func(readPixels func() (*image.RGBA, error)) {
+		a, _ := readPixels()
+		b, _ := readPixels()
+		hooked = append(hooked, a, b)
+	})
+	defer SetFrameHook(nil)
+	Draw()
+	Draw()
+	if img == nil || img.RGBAAt(5, 5) != (color.RGBA{0, 0, 255, 255}) {
+		t.Error("Screenshot does not return the frame drawn after it was called, as it was presented.")
+	}
+	if len(hooked) != 4 || hooked[0] != hooked[1] || hooked[0] != hooked[2] || hooked[0].RGBAAt(5, 5) != (color.RGBA{255, 0, 0, 255}) {
+		t.Error("The frame hook's pixels are not read once into a buffer reused for every frame.")
+	}
+
+	go func() {
+		if _, err := Screenshot(); err == nil {
+			t.Error("Screenshot returns a frame after the display was closed.")
+		}
+		shot <- nil
+	}()
+	waitForFrameRequests(t, 1)
+	CloseDisplay()
+	<-shot
+}
+
+func TestHeadlessLoadErrors(t *testing.T) {
+	h := NewHeadless()
+	if _, err := h.LoadImage("nonexistent.png"); !errors.Is(err, ErrNotFound) {
+		t.Error("Headless.LoadImage does not return ErrNotFound for missing files, returned:", err)
+	}
+	if _, err := h.LoadImage("main_test.go"); !errors.Is(err, ErrDecode) {
+		t.Error("Headless.LoadImage does not return ErrDecode for files that are not images, returned:", err)
+	}
+	if _, err := h.LoadFont("nonexistent.ttf", 12); !errors.Is(err, ErrNotFound) {
+		t.Error("Headless.LoadFont does not return ErrNotFound for missing files, returned:", err)
+	}
+}
+
+func TestHeadlessEllipses(t *testing.T) {
+	h := NewHeadless()
+	h.OpenDisplay(DisplayOptions{Width: 40, Height: 20})
+	h.Draw(func() {
+		h.FillEllipse(10, 10, 5, 3, Color{255, 0, 0, 255}, false)
+		h.DrawArc(30, 10, 6, 0, 90, Color{0, 255, 0, 255}, false)
+	})
+	f := h.Frame()
+	if f.RGBAAt(15, 10).A == 0 || f.RGBAAt(10, 13).A == 0 || f.RGBAAt(16, 10).A != 0 || f.RGBAAt(10, 14).A != 0 {
+		t.Error("Headless.FillEllipse does not reach exactly its radii.")
+	}
+	if f.RGBAAt(15, 13).A != 0 {
+		t.Error("Headless.FillEllipse fills its corners.")
+	}
+	if f.RGBAAt(36, 10).A == 0 || f.RGBAAt(30, 16).A == 0 {
+		t.Error("Headless.DrawArc does not draw from 0 to 90 degrees.")
+	}
+	if f.RGBAAt(30, 4).A != 0 || f.RGBAAt(24, 10).A != 0 || f.RGBAAt(32, 12).A != 0 {
+		t.Error("Headless.DrawArc draws outside of its arc.")
+	}
+}
+
+func TestPathMaskCoverage(t *testing.T) {
+	//a star, whose middle is only inside by the nonzero rule, off the pixel grid
+	star := []PointF{{16.3, 2.1}, {24.2, 28.4}, {2.6, 12.2}, {30.1, 12.7}, {8.4, 28.9}}
+	for _, rule := range []int{FillRule_EvenOdd, FillRule_NonZero} {
+		mask := pathMask([][]PointF{star}, rule, true, image.Rect(0, 0, 64, 64))
+		//the sample points tested one at a time
+		want := newShapeMask(mask.Bounds(), true, func(px, py float64) bool {
+			list := crossings([][]PointF{star}, py)
+			n := 0
+			winding := 0
+			for _, c := range list {
+				if c.x > px {
+					n++
+					winding += c.dir
+				}
+			}
+			if rule == FillRule_NonZero {
+				return winding != 0
+			}
+			return n%2 == 1
+		})
+		b := mask.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if got, w := mask.AlphaAt(x, y), want.At(x, y).(color.Alpha); got != w {
+					t.Fatalf("Rule %d: coverage at (%d, %d) is %d, expected %d.", rule, x, y, got.A, w.A)
+				}
+			}
+		}
+		if a := mask.AlphaAt(16, 15).A; (a == 255) != (rule == FillRule_NonZero) || (a != 0) != (rule == FillRule_NonZero) {
+			t.Errorf("Rule %d: coverage of the middle of the star is %d.", rule, a)
+		}
+	}
+}
+
+func TestMaskAlpha(t *testing.T) {
+	star := []PointF{{16.3, 2.1}, {24.2, 28.4}, {2.6, 12.2}, {30.1, 12.7}, {8.4, 28.9}}
+	masks := []image.Image{
+		filledEllipseMask(10, 10, 6, 4, true),
+		pathMask([][]PointF{star}, FillRule_NonZero, true, image.Rect(0, 0, 64, 64)),
+		image.Rect(3, 4, 9, 7),
+		&roundedRectMask{image.Rect(2, 2, 14, 12), 4},
+	}
+	for i, mask := range masks {
+		dst := image.NewAlpha(image.Rect(4, 4, 24, 24))
+		maskAlpha(dst, mask)
+		b := dst.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				_, _, _, a := mask.At(x, y).RGBA()
+				if got := dst.AlphaAt(x, y).A; got != uint8(a>>8) {
+					t.Fatalf("Mask %d: maskAlpha wrote %d at (%d, %d), expected %d.", i, got, x, y, a>>8)
+				}
+			}
+		}
+	}
+}
+
+func TestPolygonSpans(t *testing.T) {
+	//a star, whose middle is only inside by the nonzero rule
+	star := pixelCenters([]image.Point{{16, 2}, {24, 28}, {2, 12}, {30, 12}, {8, 28}})
+	for _, rule := range []int{FillRule_EvenOdd, FillRule_NonZero} {
+		mask := polygonMask(star, rule, false, image.Rect(0, 0, 64, 64))
+		in := map[image.Point]bool{}
+		for _, s := range pathSpans([][]PointF{star}, rule, image.Rect(0, 0, 64, 64)) {
+			for x := s.Min.X; x < s.Max.X; x++ {
+				in[image.Point{x, s.Min.Y}] = true
+			}
+		}
+		b := mask.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if _, _, _, a := mask.At(x, y).RGBA(); (a != 0) != in[image.Point{x, y}] {
+					t.Fatalf("Rule %d: polygon mask and spans differ at (%d, %d).", rule, x, y)
+				}
+			}
+		}
+		if middle := in[image.Point{16, 15}]; middle != (rule == FillRule_NonZero) {
+			t.Errorf("Rule %d: middle of the star filled is %v.", rule, middle)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+//Key codes share their values with SDL's keycodes.
+const scancodeMask = 1 << 30
+
+//KEY DEFINITIONS
+
+const (
+	Key_a int = 'a'
+	Key_b int = 'b'
+	Key_c int = 'c'
+	Key_d int = 'd'
+	Key_e int = 'e'
+	Key_f int = 'f'
+	Key_g int = 'g'
+	Key_h int = 'h'
+	Key_i int = 'i'
+	Key_j int = 'j'
+	Key_k int = 'k'
+	Key_l int = 'l'
+	Key_m int = 'm'
+	Key_n int = 'n'
+	Key_o int = 'o'
+	Key_p int = 'p'
+	Key_q int = 'q'
+	Key_r int = 'r'
+	Key_s int = 's'
+	Key_t int = 't'
+	Key_u int = 'u'
+	Key_v int = 'v'
+	Key_w int = 'w'
+	Key_x int = 'x'
+	Key_y int = 'y'
+	Key_z int = 'z'
+
+	Key_0 int = '0'
+	Key_1 int = '1'
+	Key_2 int = '2'
+	Key_3 int = '3'
+	Key_4 int = '4'
+	Key_5 int = '5'
+	Key_6 int = '6'
+	Key_7 int = '7'
+	Key_8 int = '8'
+	Key_9 int = '9'
+
+	Key_Colon        int = ':'
+	Key_SemiColon    int = ';'
+	Key_LessThan     int = '<'
+	Key_Equals       int = '='
+	Key_GreaterThan  int = '>'
+	Key_QuestionMark int = '?'
+	Key_At           int = '@'
+	Key_LeftBracket  int = '['
+	Key_RightBracket int = ']'
+	Key_Caret        int = '^'
+	Key_Underscore   int = '_'
+	Key_BackQuote    int = '`'
+	Key_Backspace    int = '\b'
+	Key_Tab          int = '\t'
+	Key_Enter        int = '\r'
+	Key_Pause        int = 72 | scancodeMask
+	Key_Escape       int = '\x1b'
+	Key_Space        int = ' '
+	Key_ExclaimMark  int = '!'
+	Key_DoubleQuote  int = '"'
+	Key_Hash         int = '#'
+	Key_Dollar       int = '$'
+	Key_LeftParen    int = '('
+	Key_RightParen   int = ')'
+	Key_Asterisk     int = '*'
+	Key_Plus         int = '+'
+	Key_Comma        int = ','
+	Key_Minus        int = '-'
+	Key_Period       int = '.'
+	Key_Slash        int = '/'
+	Key_Delete       int = '\x7f'
+	Key_Up           int = 82 | scancodeMask
+	Key_Down         int = 81 | scancodeMask
+	Key_Left         int = 80 | scancodeMask
+	Key_Right        int = 79 | scancodeMask
+	Key_RCtrl        int = 228 | scancodeMask
+	Key_LCtrl        int = 224 | scancodeMask
+)
@@ -16,26 +16,77 @@
 package plumbing
 
 import (
-	"io/ioutil"
-	l "log"
+	"context"
+	"log/slog"
 	"os"
+	"sync"
 )
 
-var log = l.New(ioutil.Discard, "  LOG: starfish backend: ", l.Ldate|l.Ltime)
-var errlog = l.New(ioutil.Discard, "ERROR: starfish backend: ", l.Ldate|l.Ltime)
+var loggerLock sync.RWMutex
 
-func LogOn(on bool) {
-	if on {
-		log = l.New(os.Stdout, "  LOG: starfish backend: ", l.Ldate|l.Ltime)
-	} else {
-		log = l.New(ioutil.Discard, "  LOG: starfish backend: ", l.Ldate|l.Ltime)
+//The Logger used until another is set, which writes to stdout only what LogOn and ErrLogOn have turned on.
+var stdoutLogger = slog.New(&stdoutHandler{slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})})
+var logger = stdoutLogger
+
+//Whether LogOn and ErrLogOn have turned on info and error logging to stdout.
+var logOn, errLogOn bool
+
+//Sets the Logger that all starfish packages log to. A nil Logger puts back the default one,
+//which discards all logs unless LogOn or ErrLogOn have turned them on.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = stdoutLogger
 	}
+	loggerLock.Lock()
+	logger = l
+	loggerLock.Unlock()
+}
+
+//Returns the Logger that starfish packages log to.
+func Logger() *slog.Logger {
+	loggerLock.RLock()
+	defer loggerLock.RUnlock()
+	return logger
+}
+
+//Turns logging of info and debug messages to stdout on or off. This only affects the default Logger,
+//so it does nothing while another one is set.
+//
+//Deprecated: Use SetLogger.
+func LogOn(on bool) {
+	loggerLock.Lock()
+	logOn = on
+	loggerLock.Unlock()
 }
 
+//Turns logging of warnings and errors to stdout on or off. This only affects the default Logger,
+//so it does nothing while another one is set.
+//
+//Deprecated: Use SetLogger.
 func ErrLogOn(on bool) {
-	if on {
-		errlog = l.New(os.Stdout, "ERROR: starfish backend: ", l.Ldate|l.Ltime)
-	} else {
-		errlog = l.New(ioutil.Discard, "ERROR: starfish backend: ", l.Ldate|l.Ltime)
+	loggerLock.Lock()
+	errLogOn = on
+	loggerLock.Unlock()
+}
+
+//Writes the records that LogOn and ErrLogOn have turned on.
+type stdoutHandler struct {
+	slog.Handler
+}
+
+func (me *stdoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	loggerLock.RLock()
+	defer loggerLock.RUnlock()
+	if level >= slog.LevelWarn {
+		return errLogOn
 	}
+	return logOn
+}
+
+func (me *stdoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stdoutHandler{me.Handler.WithAttrs(attrs)}
+}
+
+func (me *stdoutHandler) WithGroup(name string) slog.Handler {
+	return &stdoutHandler{me.Handler.WithGroup(name)}
 }
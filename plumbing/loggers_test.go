@@ -0,0 +1,74 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	if _, err := LoadImage("testdata/missing.png"); err == nil {
+		t.Fatal("Loading a missing image succeeded")
+	}
+	out := buf.String()
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "path=testdata/missing.png") {
+		t.Errorf("Expected a warning with the path, got: %q", out)
+	}
+
+	buf.Reset()
+	SetLogger(nil)
+	LoadImage("testdata/missing.png")
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing to be logged after SetLogger(nil), got: %q", buf.String())
+	}
+}
+
+func TestLogOnKeepsLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	SetLogger(l)
+	defer SetLogger(nil)
+	LogOn(true)
+	defer LogOn(false)
+	ErrLogOn(true)
+	defer ErrLogOn(false)
+
+	if Logger() != l {
+		t.Fatal("LogOn and ErrLogOn replaced the Logger set by SetLogger")
+	}
+	Logger().Warn("kept")
+	if !strings.Contains(buf.String(), "msg=kept") {
+		t.Errorf("Expected the set Logger to still be logged to, got: %q", buf.String())
+	}
+
+	SetLogger(nil)
+	ctx := context.Background()
+	if !Logger().Enabled(ctx, slog.LevelDebug) || !Logger().Enabled(ctx, slog.LevelError) {
+		t.Error("The default Logger does not log what LogOn and ErrLogOn turned on")
+	}
+	LogOn(false)
+	if Logger().Enabled(ctx, slog.LevelInfo) || !Logger().Enabled(ctx, slog.LevelWarn) {
+		t.Error("LogOn(false) did not turn off only info and debug logging")
+	}
+}
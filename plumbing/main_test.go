@@ -18,6 +18,7 @@ package plumbing
 import "testing"
 
 func TestMain(t *testing.T) {
+	SetBackend(NewHeadless())
 	OpenDisplay(800, 600, false)
 	SetDisplayTitle("Narf!")
 }
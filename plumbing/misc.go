@@ -21,17 +21,80 @@ var KeyDown = func(e KeyEvent) {}
 var MouseWheelFunc = func(e MouseWheelEvent) {}
 var MouseButtonUp = func(e MouseEvent) {}
 var MouseButtonDown = func(e MouseEvent) {}
+var WindowFunc = func(e WindowEvent) {}
+
+var inputChan = make(chan interface{}, 10)
+
+type QuitEvent struct{}
 
 type KeyEvent struct {
-	Key int
+	Key     int
+	Pressed bool
 }
 
 type MouseEvent struct {
-	X, Y   int
-	Button int
+	X, Y    int
+	Button  int
+	Pressed bool
 }
 
 type MouseWheelEvent struct {
 	X, Y int
 	Up   bool
 }
+
+//Types of WindowEvent.
+const (
+	//The window changed size.
+	Window_Resized = iota
+	Window_FocusGained
+	Window_FocusLost
+	Window_Minimized
+	Window_Maximized
+	//The window was restored from being minimized or maximized.
+	Window_Restored
+	//The mouse entered the window.
+	Window_MouseEnter
+	//The mouse left the window.
+	Window_MouseLeave
+	//Part of the window was uncovered and needs to be redrawn.
+	Window_Exposed
+)
+
+type WindowEvent struct {
+	Type int
+	//The new size of the display, for Window_Resized.
+	Width, Height int
+}
+
+//Queues an input event to be passed on to the input handlers.
+//Takes a QuitEvent, KeyEvent, MouseEvent, MouseWheelEvent, or WindowEvent.
+func PostEvent(e interface{}) {
+	inputChan <- e
+}
+
+func HandleInput() {
+	for running := true; running; {
+		switch e := (<-inputChan).(type) {
+		case QuitEvent:
+			go QuitFunc()
+			running = false
+		case KeyEvent:
+			if e.Pressed {
+				go KeyDown(e)
+			} else {
+				go KeyUp(e)
+			}
+		case MouseWheelEvent:
+			go MouseWheelFunc(e)
+		case WindowEvent:
+			go WindowFunc(e)
+		case MouseEvent:
+			if e.Pressed {
+				go MouseButtonDown(e)
+			} else {
+				go MouseButtonUp(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,404 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+//Masks for drawing shapes with the Headless Backend, and the polygon spans the SDL Backend fills.
+
+//Rules for which parts of a polygon are inside it, where its edges cross.
+const (
+	//Points are inside if a ray from them crosses the edges an odd number of times.
+	FillRule_EvenOdd = iota
+	//Points are inside if the edges wind around them, where edges going opposite ways cancel out.
+	FillRule_NonZero
+)
+
+//The samples per side of a pixel that antialiased shapes are drawn with.
+const aaSamples = 4
+
+//An alpha mask of a shape, where each pixel is as opaque as the share of its sample points inside the shape.
+//Aliased shapes have one sample point per pixel, at its center.
+type shapeMask struct {
+	bounds  image.Rectangle
+	samples int
+	inside  func(x, y float64) bool
+}
+
+func newShapeMask(bounds image.Rectangle, antialias bool, inside func(x, y float64) bool) *shapeMask {
+	samples := 1
+	if antialias {
+		samples = aaSamples
+	}
+	return &shapeMask{bounds, samples, inside}
+}
+
+func (me *shapeMask) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (me *shapeMask) Bounds() image.Rectangle {
+	return me.bounds
+}
+
+func (me *shapeMask) At(x, y int) color.Color {
+	if !(image.Point{x, y}).In(me.bounds) {
+		return color.Transparent
+	}
+	return color.Alpha{me.coverage(x, y)}
+}
+
+//Returns how opaque the pixel is, which must be within the mask's bounds.
+func (me *shapeMask) coverage(x, y int) uint8 {
+	n := 0
+	step := 1 / float64(me.samples)
+	for i := 0; i < me.samples; i++ {
+		for j := 0; j < me.samples; j++ {
+			if me.inside(float64(x)+(float64(i)+0.5)*step, float64(y)+(float64(j)+0.5)*step) {
+				n++
+			}
+		}
+	}
+	return uint8(n * 255 / (me.samples * me.samples))
+}
+
+//Writes how opaque the mask is at each pixel within dst's bounds into dst, without boxing a color per pixel
+//for the masks drawn most.
+func maskAlpha(dst *image.Alpha, mask image.Image) {
+	b := dst.Bounds()
+	for i := range dst.Pix {
+		dst.Pix[i] = 0
+	}
+	switch m := mask.(type) {
+	case *image.Alpha:
+		r := b.Intersect(m.Bounds())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			copy(dst.Pix[dst.PixOffset(r.Min.X, y):dst.PixOffset(r.Max.X, y)], m.Pix[m.PixOffset(r.Min.X, y):])
+		}
+	case image.Rectangle:
+		r := b.Intersect(m)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row := dst.Pix[dst.PixOffset(r.Min.X, y):dst.PixOffset(r.Max.X, y)]
+			for i := range row {
+				row[i] = 255
+			}
+		}
+	case *shapeMask:
+		r := b.Intersect(m.bounds)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				dst.Pix[dst.PixOffset(x, y)] = m.coverage(x, y)
+			}
+		}
+	default:
+		r := b.Intersect(mask.Bounds())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				_, _, _, a := mask.At(x, y).RGBA()
+				dst.Pix[dst.PixOffset(x, y)] = uint8(a >> 8)
+			}
+		}
+	}
+}
+
+//Returns a mask of the pixels on the line from (x1, y1) to (x2, y2) within clip, including both ends.
+//Only the steps along the line's longer axis that are within clip are walked, each finding how far it has
+//stepped along the shorter axis as Bresenham's algorithm would have, so the pixels are those of the whole line.
+func lineMask(x1, y1, x2, y2 int, clip image.Rectangle) *image.Alpha {
+	bounds := image.Rect(x1, y1, x1+1, y1+1).Union(image.Rect(x2, y2, x2+1, y2+1)).Intersect(clip)
+	mask := image.NewAlpha(bounds)
+	if bounds.Empty() {
+		return mask
+	}
+	dx, dy := abs(x2-x1), abs(y2-y1)
+	sx, sy := 1, 1
+	if x2 < x1 {
+		sx = -1
+	}
+	if y2 < y1 {
+		sy = -1
+	}
+	steps, rise := dx, dy
+	start, step, lo, hi := x1, sx, bounds.Min.X, bounds.Max.X
+	if dy > dx {
+		steps, rise = dy, dx
+		start, step, lo, hi = y1, sy, bounds.Min.Y, bounds.Max.Y
+	}
+	//the steps whose pixels are within the bounds along the longer axis
+	first, last := max(lo-start, 0), min(hi-1-start, steps)
+	if step < 0 {
+		first, last = max(start-hi+1, 0), min(start-lo, steps)
+	}
+	for i := first; i <= last; i++ {
+		//the steps along the shorter axis, rounding halves up, so there is one pixel per column or row
+		//as SDL draws lines
+		k := 0
+		if steps != 0 {
+			k = (2*rise*i + steps) / (2 * steps)
+		}
+		x, y := x1+sx*i, y1+sy*k
+		if dy > dx {
+			x, y = x1+sx*k, y1+sy*i
+		}
+		if (image.Point{x, y}).In(bounds) {
+			mask.SetAlpha(x, y, color.Alpha{255})
+		}
+	}
+	return mask
+}
+
+//Returns a mask of a line width pixels wide, with square ends half a pixel past the end points,
+//so that it covers the pixels at both ends as SDL_gfx's thick lines do. The mask covers only the part
+//of the line within clip.
+func thickLineMask(x1, y1, x2, y2, width int, antialias bool, clip image.Rectangle) *image.Alpha {
+	cx1, cy1 := float64(x1)+0.5, float64(y1)+0.5
+	cx2, cy2 := float64(x2)+0.5, float64(y2)+0.5
+	length := math.Hypot(cx2-cx1, cy2-cy1)
+	if length == 0 {
+		h := float64(width) / 2
+		return polygonMask([]PointF{{cx1 - h, cy1 - h}, {cx1 + h, cy1 - h}, {cx1 + h, cy1 + h}, {cx1 - h, cy1 + h}}, FillRule_EvenOdd, antialias, clip)
+	}
+	//half a pixel along the line
+	ux, uy := (cx2-cx1)/length/2, (cy2-cy1)/length/2
+	cx1, cy1, cx2, cy2 = cx1-ux, cy1-uy, cx2+ux, cy2+uy
+	//the normal to the line, half the width long
+	nx := -uy * float64(width)
+	ny := ux * float64(width)
+	return polygonMask([]PointF{{cx1 + nx, cy1 + ny}, {cx2 + nx, cy2 + ny}, {cx2 - nx, cy2 - ny}, {cx1 - nx, cy1 - ny}}, FillRule_EvenOdd, antialias, clip)
+}
+
+//Returns the centers of the given pixels.
+func pixelCenters(points []image.Point) []PointF {
+	centers := make([]PointF, len(points))
+	for i, pt := range points {
+		centers[i] = PointF{float64(pt.X) + 0.5, float64(pt.Y) + 0.5}
+	}
+	return centers
+}
+
+func contourBounds(contours [][]PointF) image.Rectangle {
+	var bounds image.Rectangle
+	for _, points := range contours {
+		if len(points) == 0 {
+			continue
+		}
+		minX, minY, maxX, maxY := points[0].X, points[0].Y, points[0].X, points[0].Y
+		for _, pt := range points[1:] {
+			minX, maxX = math.Min(minX, pt.X), math.Max(maxX, pt.X)
+			minY, maxY = math.Min(minY, pt.Y), math.Max(maxY, pt.Y)
+		}
+		bounds = bounds.Union(image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1))
+	}
+	return bounds
+}
+
+//An edge of a polygon crossing a horizontal line.
+type crossing struct {
+	x float64
+	//1 if the edge goes down, -1 if it goes up
+	dir int
+}
+
+//Returns where the edges of the closed contours cross the horizontal line at y, from left to right.
+func crossings(contours [][]PointF, y float64) []crossing {
+	var list []crossing
+	for _, points := range contours {
+		for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+			a, b := points[j], points[i]
+			if (a.Y > y) != (b.Y > y) {
+				c := crossing{a.X + (y-a.Y)*(b.X-a.X)/(b.Y-a.Y), 1}
+				if b.Y < a.Y {
+					c.dir = -1
+				}
+				list = append(list, c)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].x < list[j].x })
+	return list
+}
+
+//Calls f with where each stretch of the line the crossings are on that is inside of the path starts and ends.
+func insideSpans(list []crossing, rule int, f func(x1, x2 float64)) {
+	winding := 0
+	for i := 0; i+1 < len(list); i++ {
+		winding += list[i].dir
+		in := winding != 0
+		if rule == FillRule_EvenOdd {
+			in = i%2 == 0
+		}
+		if in {
+			f(list[i].x, list[i+1].x)
+		}
+	}
+}
+
+//Returns a mask of the inside of a polygon, within clip.
+func polygonMask(points []PointF, rule int, antialias bool, clip image.Rectangle) *image.Alpha {
+	return pathMask([][]PointF{points}, rule, antialias, clip)
+}
+
+//Returns a mask of the inside of a path made of the given closed contours, where each pixel is as opaque as
+//the share of its sample points inside the path. The crossings are found once per row of sample points,
+//and the sample points between them counted off along the row. The mask covers only the part of the path
+//within clip, so paths reaching far past what is drawn to cost no more than what is drawn.
+func pathMask(contours [][]PointF, rule int, antialias bool, clip image.Rectangle) *image.Alpha {
+	bounds := contourBounds(contours).Intersect(clip)
+	mask := image.NewAlpha(bounds)
+	samples := 1
+	if antialias {
+		samples = aaSamples
+	}
+	s := float64(samples)
+	first, last := bounds.Min.X*samples, bounds.Max.X*samples
+	counts := make([]int, bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for i := range counts {
+			counts[i] = 0
+		}
+		for j := 0; j < samples; j++ {
+			insideSpans(crossings(contours, float64(y)+(float64(j)+0.5)/s), rule, func(x1, x2 float64) {
+				//the sample points from x1 up to but not including x2
+				k1 := max(int(math.Ceil(x1*s-0.5)), first)
+				k2 := min(int(math.Ceil(x2*s-0.5)), last)
+				for k := k1; k < k2; k++ {
+					counts[(k-first)/samples]++
+				}
+			})
+		}
+		row := mask.Pix[mask.PixOffset(bounds.Min.X, y):]
+		for x, n := range counts {
+			row[x] = uint8(n * 255 / (samples * samples))
+		}
+	}
+	return mask
+}
+
+//Returns the rows of pixels whose centers are inside of the path made of the given closed contours,
+//as rectangles 1 pixel high, within clip.
+func pathSpans(contours [][]PointF, rule int, clip image.Rectangle) []image.Rectangle {
+	var spans []image.Rectangle
+	bounds := contourBounds(contours).Intersect(clip)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		insideSpans(crossings(contours, float64(y)+0.5), rule, func(x1, x2 float64) {
+			//the pixels whose centers are between the crossings
+			if p1, p2 := max(int(math.Ceil(x1-0.5)), bounds.Min.X), min(int(math.Ceil(x2-0.5)), bounds.Max.X); p2 > p1 {
+				spans = append(spans, image.Rect(p1, y, p2, y+1))
+			}
+		})
+	}
+	return spans
+}
+
+//Returns the rows of pixels that are not transparent in the given mask, as rectangles 1 pixel high.
+func maskSpans(mask image.Image) []image.Rectangle {
+	a, ok := mask.(*image.Alpha)
+	if !ok {
+		a = image.NewAlpha(mask.Bounds())
+		maskAlpha(a, mask)
+	}
+	var spans []image.Rectangle
+	b := a.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := a.Pix[a.PixOffset(b.Min.X, y):a.PixOffset(b.Max.X, y)]
+		start := -1
+		for x := 0; x <= len(row); x++ {
+			in := x < len(row) && row[x] != 0
+			if in && start < 0 {
+				start = x
+			} else if !in && start >= 0 {
+				spans = append(spans, image.Rect(b.Min.X+start, y, b.Min.X+x, y+1))
+				start = -1
+			}
+		}
+	}
+	return spans
+}
+
+//Returns the bounds of the ellipse centered on the pixel (x, y) with the given radii in pixels.
+func ellipseBounds(x, y, rx, ry int) image.Rectangle {
+	return image.Rect(x-rx-1, y-ry-1, x+rx+2, y+ry+2)
+}
+
+//Reports whether the point is within the ellipse centered on the pixel (x, y) that reaches
+//the centers of the pixels rx and ry away, padded by pad.
+func inEllipse(px, py float64, x, y int, rx, ry, pad float64) bool {
+	a, b := rx+pad, ry+pad
+	if a <= 0 || b <= 0 {
+		return false
+	}
+	dx, dy := (px-float64(x)-0.5)/a, (py-float64(y)-0.5)/b
+	return dx*dx+dy*dy <= 1
+}
+
+//Reports whether the point is within the angles from start to end degrees of the pixel (x, y),
+//going clockwise from the positive x axis.
+func inAngles(px, py float64, x, y, start, end int) bool {
+	dx, dy := px-float64(x)-0.5, py-float64(y)-0.5
+	if dx == 0 && dy == 0 {
+		return true
+	}
+	a := math.Atan2(dy, dx) * 180 / math.Pi
+	span := math.Mod(float64(end-start), 360)
+	if span < 0 {
+		span += 360
+	}
+	from := math.Mod(a-float64(start), 360)
+	if from < 0 {
+		from += 360
+	}
+	return from <= span
+}
+
+func filledEllipseMask(x, y, rx, ry int, antialias bool) *shapeMask {
+	return newShapeMask(ellipseBounds(x, y, rx, ry), antialias, func(px, py float64) bool {
+		return inEllipse(px, py, x, y, float64(rx), float64(ry), 0.5)
+	})
+}
+
+//Returns a mask of a 1 pixel wide outline of the ellipse.
+func ellipseMask(x, y, rx, ry int, antialias bool) *shapeMask {
+	return newShapeMask(ellipseBounds(x, y, rx, ry), antialias, func(px, py float64) bool {
+		return inEllipse(px, py, x, y, float64(rx), float64(ry), 0.5) && !inEllipse(px, py, x, y, float64(rx), float64(ry), -0.5)
+	})
+}
+
+//Returns a mask of a 1 pixel wide arc of the circle.
+func arcMask(x, y, radius, start, end int, antialias bool) *shapeMask {
+	r := float64(radius)
+	return newShapeMask(ellipseBounds(x, y, radius, radius), antialias, func(px, py float64) bool {
+		return inEllipse(px, py, x, y, r, r, 0.5) && !inEllipse(px, py, x, y, r, r, -0.5) && inAngles(px, py, x, y, start, end)
+	})
+}
+
+func pieMask(x, y, radius, start, end int, antialias bool) *shapeMask {
+	r := float64(radius)
+	return newShapeMask(ellipseBounds(x, y, radius, radius), antialias, func(px, py float64) bool {
+		return inEllipse(px, py, x, y, r, r, 0.5) && inAngles(px, py, x, y, start, end)
+	})
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
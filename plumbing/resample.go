@@ -0,0 +1,154 @@
+/*
+   Copyright 2011-2014 starfish authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package plumbing
+
+import (
+	"image"
+	"math"
+)
+
+//Resampling for ResizeAngleOf, which both Backends do in Go so they produce the same pixels.
+
+//How source pixels are blended when an image is resized or rotated by ResizeAngleOf.
+const (
+	//Takes the source pixel nearest each new pixel, keeping hard edges.
+	Filter_Nearest int = iota
+	//Blends the 2x2 source pixels around each new pixel.
+	Filter_Bilinear
+	//Blends the 4x4 source pixels around each new pixel, which keeps scaled up images sharper than bilinear.
+	Filter_Bicubic
+)
+
+//Returns the size of the bounding box of a w by h image rotated by angle degrees.
+func rotatedSize(w, h int, angle float64) (int, int) {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	sin, cos = math.Abs(sin), math.Abs(cos)
+	//rounding error would otherwise add a pixel at right angles
+	const epsilon = 1e-9
+	rw := math.Ceil(float64(w)*cos + float64(h)*sin - epsilon)
+	rh := math.Ceil(float64(w)*sin + float64(h)*cos - epsilon)
+	return int(rw), int(rh)
+}
+
+//Returns a new image of src stretched to w by h, then rotated by angle degrees clockwise around its center.
+//The new image is the size of the rotated image's bounding box, and transparent outside of it.
+func resample(src *image.RGBA, angle float64, w, h, filter int) *image.RGBA {
+	rw, rh := rotatedSize(w, h, angle)
+	dst := image.NewRGBA(image.Rect(0, 0, rw, rh))
+	sb := src.Bounds()
+	if sb.Empty() || w <= 0 || h <= 0 {
+		return dst
+	}
+	sx, sy := float64(sb.Dx())/float64(w), float64(sb.Dy())/float64(h)
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	for y := 0; y < rh; y++ {
+		for x := 0; x < rw; x++ {
+			//rotate the pixel's center back into the stretched image, then scale it into the source
+			dx, dy := float64(x)+0.5-float64(rw)/2, float64(y)+0.5-float64(rh)/2
+			u := (dx*cos+dy*sin+float64(w)/2)*sx + float64(sb.Min.X)
+			v := (-dx*sin+dy*cos+float64(h)/2)*sy + float64(sb.Min.Y)
+			i := dst.PixOffset(x, y)
+			if filter == Filter_Nearest {
+				px, py := int(math.Floor(u)), int(math.Floor(v))
+				if image.Pt(px, py).In(sb) {
+					copy(dst.Pix[i:i+4], src.Pix[src.PixOffset(px, py):])
+				}
+				continue
+			}
+			sample(src, u, v, sx, sy, filter, dst.Pix[i:i+4])
+		}
+	}
+	return dst
+}
+
+//Writes the source blended around (u, v) with the filter's kernel into out.
+//The kernel is widened by the scale when shrinking, so every source pixel counts toward the new ones.
+//Pixels past the source's edges repeat them, and new pixels are faded by how much of them the
+//stretched image covers, which smooths rotated edges without fading the edges of unrotated ones.
+func sample(src *image.RGBA, u, v, sx, sy float64, filter int, out []uint8) {
+	radius, weight := 1.0, tent
+	if filter == Filter_Bicubic {
+		radius, weight = 2, catmullRom
+	}
+	sb := src.Bounds()
+	//how far the new pixel's center is inside the stretched image, in new pixels
+	inside := math.Min(math.Min(u-float64(sb.Min.X), float64(sb.Max.X)-u)/sx, math.Min(v-float64(sb.Min.Y), float64(sb.Max.Y)-v)/sy)
+	coverage := math.Min(math.Max(inside+0.5, 0), 1)
+	if coverage == 0 {
+		return
+	}
+	kx, ky := math.Max(sx, 1), math.Max(sy, 1)
+	u, v = u-0.5, v-0.5
+	x0, x1 := int(math.Ceil(u-radius*kx)), int(math.Floor(u+radius*kx))
+	y0, y1 := int(math.Ceil(v-radius*ky)), int(math.Floor(v+radius*ky))
+	var acc [4]float64
+	var total float64
+	for py := y0; py <= y1; py++ {
+		wy := weight((float64(py) - v) / ky)
+		if wy == 0 {
+			continue
+		}
+		for px := x0; px <= x1; px++ {
+			wt := wy * weight((float64(px)-u)/kx)
+			if wt == 0 {
+				continue
+			}
+			total += wt
+			p := src.Pix[src.PixOffset(clamp(px, sb.Min.X, sb.Max.X-1), clamp(py, sb.Min.Y, sb.Max.Y-1)):]
+			for c := range acc {
+				acc[c] += wt * float64(p[c])
+			}
+		}
+	}
+	if total == 0 {
+		return
+	}
+	//bicubic overshoots, so keep the alpha in range and the premultiplied colors within it
+	a := math.Min(math.Max(acc[3]/total, 0), 255)
+	for c := 0; c < 3; c++ {
+		out[c] = uint8(math.Round(math.Min(math.Max(acc[c]/total, 0), a) * coverage))
+	}
+	out[3] = uint8(math.Round(a * coverage))
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func tent(t float64) float64 {
+	t = math.Abs(t)
+	if t >= 1 {
+		return 0
+	}
+	return 1 - t
+}
+
+func catmullRom(t float64) float64 {
+	t = math.Abs(t)
+	switch {
+	case t < 1:
+		return (1.5*t-2.5)*t*t + 1
+	case t < 2:
+		return ((-0.5*t+2.5)*t-4)*t + 2
+	}
+	return 0
+}
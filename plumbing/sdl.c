@@ -1,3 +1,5 @@
+//go:build !nosdl
+
 /*
  * Copyright 2011-2014 starfish authors
  * 
@@ -19,16 +21,24 @@
 SDL_Window *screen;
 SDL_mutex *mainMut;
 
-SDL_Window *openDisplay(int w, int h, int full) {
+SDL_Window *openDisplay(int x, int y, int w, int h, Uint32 flags) {
 	SDL_Init(SDL_INIT_VIDEO | SDL_INIT_EVENTS | SDL_INIT_TIMER);
 	IMG_Init(IMG_INIT_PNG);
 	TTF_Init();
-	unsigned flags = 0;
-	if (full) {
-		flags |= SDL_WINDOW_FULLSCREEN;
-	}
 	flags |= SDL_WINDOW_OPENGL;
-	screen = SDL_CreateWindow("", SDL_WINDOWPOS_UNDEFINED, SDL_WINDOWPOS_UNDEFINED, w, h, flags);
+	if (w <= 0 || h <= 0) {
+		// a size of 0 uses the size of the screen
+		SDL_DisplayMode mode;
+		if (SDL_GetCurrentDisplayMode(0, &mode) == 0) {
+			if (w <= 0) {
+				w = mode.w;
+			}
+			if (h <= 0) {
+				h = mode.h;
+			}
+		}
+	}
+	screen = SDL_CreateWindow("", x, y, w, h, flags);
 	mainMut = SDL_CreateMutex();
 	SDL_LockMutex(mainMut);
 	return screen;
@@ -73,3 +83,7 @@ int eventMouseY(SDL_Event *e) {
 int eventMouseWheelY(SDL_Event *e) {
 	return e->wheel.y;
 }
+
+int eventWindowEvent(SDL_Event *e) {
+	return e->window.event;
+}
@@ -1,3 +1,5 @@
+//go:build !nosdl
+
 /*
    Copyright 2011-2014 starfish authors
 
@@ -26,44 +28,116 @@ package plumbing
 */
 import "C"
 import (
+	"errors"
+	"image"
+	"image/draw"
+	"os"
 	"runtime"
 	"sync"
+	"unsafe"
 )
 
 func init() {
 	runtime.LockOSThread()
+	backend = &sdlBackend{blend: C.SDL_BLENDMODE_BLEND, imageBlend: C.SDL_BLENDMODE_BLEND, imageColor: Color{255, 255, 255, 255}}
 }
 
-var drawFunc = func() {}
 var screen *C.SDL_Window
 var renderer *C.SDL_Renderer
-var inputChan = make(chan C.SDL_Event, 10)
+var drawChan = make(chan func())
 var drawComplete = make(chan interface{})
 
 var Event_DrawEvent, Event_MainOpEvent C.Uint32
 var mainOpMutex sync.Mutex
 var mainOpChan = make(chan func())
 
+//The Backend implementation built on SDL2.
+type sdlBackend struct {
+	paint      image.Image
+	mode       int
+	blend      C.SDL_BlendMode
+	imageBlend C.SDL_BlendMode
+	imageColor Color
+	//reused by drawMask, so shapes do not allocate pixels or a texture each time they are drawn
+	maskTex     *C.SDL_Texture
+	maskTexSize image.Point
+	maskSrc     *image.RGBA
+	maskCover   *image.Alpha
+	maskPix     []uint8
+}
+
 func isMainThread() bool {
 	return C.isMainThread() != 0
 }
 
-func OpenDisplay(w, h int, full bool) {
+//Returns an Error of the given kind with SDL's message for the last error.
+func sdlError(kind error, op, path string) error {
+	return &Error{Kind: kind, Op: op, Path: path, Msg: C.GoString(C.SDL_GetError())}
+}
+
+//Returns an Error of the given kind for a file SDL could not load, or ErrNotFound if the file does not exist.
+func sdlLoadError(kind error, op, path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &Error{Kind: ErrNotFound, Op: op, Path: path, Msg: err.Error()}
+	}
+	return sdlError(kind, op, path)
+}
+
+func (me *sdlBackend) OpenDisplay(opts DisplayOptions) error {
 	runtime.LockOSThread()
-	var i C.int = 0
-	if full {
-		i = 1
+	var flags C.Uint32
+	if opts.Fullscreen {
+		flags |= C.SDL_WINDOW_FULLSCREEN
+	}
+	if opts.Resizable {
+		flags |= C.SDL_WINDOW_RESIZABLE
+	}
+	if opts.Borderless {
+		flags |= C.SDL_WINDOW_BORDERLESS
+	}
+	if opts.HighDPI {
+		flags |= C.SDL_WINDOW_ALLOW_HIGHDPI
+	}
+	x, y := C.int(C.SDL_WINDOWPOS_UNDEFINED), C.int(C.SDL_WINDOWPOS_UNDEFINED)
+	if opts.Position != nil {
+		x, y = C.int(opts.Position.X), C.int(opts.Position.Y)
+	}
+	screen = C.openDisplay(x, y, C.int(opts.Width), C.int(opts.Height), flags)
+	if screen == nil {
+		return sdlError(ErrDisplay, "OpenDisplay", "")
+	}
+	if opts.MinWidth > 0 || opts.MinHeight > 0 {
+		C.SDL_SetWindowMinimumSize(screen, C.int(opts.MinWidth), C.int(opts.MinHeight))
+	}
+	var rflags C.Uint32 = C.SDL_RENDERER_ACCELERATED | C.SDL_RENDERER_TARGETTEXTURE
+	if opts.Software {
+		rflags = C.SDL_RENDERER_SOFTWARE | C.SDL_RENDERER_TARGETTEXTURE
+	}
+	if opts.VSync {
+		rflags |= C.SDL_RENDERER_PRESENTVSYNC
+	}
+	renderer = C.SDL_CreateRenderer(screen, -1, rflags)
+	if renderer == nil {
+		return sdlError(ErrNoRenderer, "OpenDisplay", "")
+	}
+	C.SDL_SetRenderDrawBlendMode(renderer, me.blend)
+	if opts.Icon != nil {
+		if err := me.SetDisplayIcon(opts.Icon); err != nil {
+			return err
+		}
 	}
-	screen = C.openDisplay(C.int(w), C.int(h), i)
-	renderer = C.SDL_CreateRenderer(screen, -1, C.SDL_RENDERER_ACCELERATED)
-	C.SDL_SetRenderDrawBlendMode(renderer, C.SDL_BLENDMODE_BLEND)
 
 	Event_DrawEvent = C.SDL_RegisterEvents(1)
 	Event_MainOpEvent = C.SDL_RegisterEvents(1)
+	return nil
 }
 
-func CloseDisplay() {
+func (me *sdlBackend) CloseDisplay() {
 	runMainOp(func() {
+		if me.maskTex != nil {
+			C.SDL_DestroyTexture(me.maskTex)
+			me.maskTex = nil
+		}
 		C.closeDisplay()
 	})
 }
@@ -88,41 +162,129 @@ func runMainOp(f func()) {
 	}
 }
 
-func SetDrawFunc(f func()) {
-	drawFunc = f
-}
-
 //Sets the title of the window.
-func SetDisplayTitle(title string) {
+func (me *sdlBackend) SetDisplayTitle(title string) {
 	C.SDL_SetWindowTitle(screen, C.CString(title))
 }
 
-//Returns the width of the display window.
-func DisplayWidth() int {
-	var w, h C.int
-	C.SDL_GetWindowSize(screen, &w, &h)
-	return int(w)
+//Sets the icon of the window.
+func (me *sdlBackend) SetDisplayIcon(icon image.Image) error {
+	b := icon.Bounds()
+	if b.Empty() {
+		return &Error{Kind: ErrTexture, Op: "SetDisplayIcon", Msg: "icon is empty"}
+	}
+	pix := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(pix, pix.Bounds(), icon, b.Min, draw.Src)
+	var err error
+	runMainOp(func() {
+		sur := C.SDL_CreateRGBSurfaceWithFormatFrom(unsafe.Pointer(&pix.Pix[0]), C.int(b.Dx()), C.int(b.Dy()), 32, C.int(pix.Stride), C.SDL_PIXELFORMAT_RGBA32)
+		if sur == nil {
+			err = sdlError(ErrTexture, "SetDisplayIcon", "")
+			return
+		}
+		//SDL copies the icon, so the surface and pixels can be freed right away
+		C.SDL_SetWindowIcon(screen, sur)
+		C.SDL_FreeSurface(sur)
+	})
+	return err
 }
 
-//Returns the height of the display window.
-func DisplayHeight() int {
+//Returns the size of the display, which is in pixels rather than screen points for high-DPI displays.
+func (me *sdlBackend) DisplaySize() (int, int) {
 	var w, h C.int
-	C.SDL_GetWindowSize(screen, &w, &h)
-	return int(h)
+	if renderer != nil {
+		C.SDL_GetRendererOutputSize(renderer, &w, &h)
+	} else {
+		C.SDL_GetWindowSize(screen, &w, &h)
+	}
+	return int(w), int(h)
+}
+
+//Converts a point from screen points to pixels, for high-DPI displays.
+func toPixels(x, y C.int) (int, int) {
+	var ww, wh, pw, ph C.int
+	C.SDL_GetWindowSize(screen, &ww, &wh)
+	C.SDL_GetRendererOutputSize(renderer, &pw, &ph)
+	if ww == 0 || wh == 0 || (ww == pw && wh == ph) {
+		return int(x), int(y)
+	}
+	return int(x * pw / ww), int(y * ph / wh)
 }
 
 //Used to manually draw the screen.
-func Draw() {
+func (me *sdlBackend) Draw(f func()) {
 	var e C.SDL_Event
 	C.setEventType(&e, Event_DrawEvent)
 	C.SDL_PushEvent(&e)
+	drawChan <- f
 	<-drawComplete
 }
 
-func Clear() {
+//Restores the render target, clip rect, draw color and blend mode once f returns.
+func (me *sdlBackend) DrawTo(target *Image, f func()) {
+	runMainOp(func() {
+		prev := C.SDL_GetRenderTarget(renderer)
+		var clip C.SDL_Rect
+		clipped := C.SDL_RenderIsClipEnabled(renderer) == C.SDL_TRUE
+		C.SDL_RenderGetClipRect(renderer, &clip)
+		var r, g, b, a C.Uint8
+		C.SDL_GetRenderDrawColor(renderer, &r, &g, &b, &a)
+		mode := me.mode
+		C.SDL_SetRenderTarget(renderer, sdlTexture(target))
+		f()
+		me.SetBlendMode(mode)
+		C.SDL_SetRenderTarget(renderer, prev)
+		if clipped {
+			C.SDL_RenderSetClipRect(renderer, &clip)
+		} else {
+			C.SDL_RenderSetClipRect(renderer, nil)
+		}
+		C.SDL_SetRenderDrawColor(renderer, r, g, b, a)
+	})
+}
+
+func (me *sdlBackend) Clear(c Color) {
+	C.SDL_SetRenderDrawColor(renderer, C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha))
 	C.SDL_RenderClear(renderer)
 }
 
+//Returns the size of the current render target.
+func outputSize() (w, h C.int) {
+	if t := C.SDL_GetRenderTarget(renderer); t != nil {
+		C.SDL_QueryTexture(t, nil, nil, &w, &h)
+	} else {
+		C.SDL_GetRendererOutputSize(renderer, &w, &h)
+	}
+	return
+}
+
+//Returns the bounds of the current render target, which shapes are clipped to before they are masked.
+func outputBounds() image.Rectangle {
+	w, h := outputSize()
+	return image.Rect(0, 0, int(w), int(h))
+}
+
+//Reads the pixels of the current render target, which must be done before the frame is presented.
+func (me *sdlBackend) ReadPixels(img *image.RGBA) (*image.RGBA, error) {
+	w, h := outputSize()
+	if r := image.Rect(0, 0, int(w), int(h)); img == nil || img.Rect != r {
+		img = image.NewRGBA(r)
+	}
+	if w == 0 || h == 0 {
+		return img, nil
+	}
+	if C.SDL_RenderReadPixels(renderer, nil, C.SDL_PIXELFORMAT_RGBA32, unsafe.Pointer(&img.Pix[0]), C.int(img.Stride)) != 0 {
+		return nil, errors.New(C.GoString(C.SDL_GetError()))
+	}
+	if C.SDL_GetRenderTarget(renderer) == nil {
+		//the window has no meaningful alpha
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+	}
+	return img, nil
+}
+
 //MISC
 
 func sdl_Rect(x, y, width, height int) C.SDL_Rect {
@@ -134,11 +296,6 @@ func sdl_Rect(x, y, width, height int) C.SDL_Rect {
 	return r
 }
 
-//An RGB color representation.
-type Color struct {
-	Red, Green, Blue, Alpha byte
-}
-
 func (me *Color) toSDL_Color() C.SDL_Color {
 	return C.SDL_Color{C.Uint8(me.Red), C.Uint8(me.Green), C.Uint8(me.Blue), C.Uint8(me.Alpha)}
 }
@@ -149,123 +306,488 @@ func (me *Color) toUint32() uint32 {
 
 //IMAGE HANDLING
 
-type Image struct {
-	surface       *C.SDL_Texture
-	Width, Height int
+func sdlTexture(img *Image) *C.SDL_Texture {
+	t, _ := img.Texture.(*C.SDL_Texture)
+	return t
 }
 
-func (me *Image) W() int {
-	var out C.int
-	C.SDL_QueryTexture(me.surface, nil, nil, &out, nil)
-	return int(out)
-}
-
-func (me *Image) H() int {
-	var out C.int
-	C.SDL_QueryTexture(me.surface, nil, nil, nil, &out)
-	return int(out)
+func (me *sdlBackend) TextureSize(img *Image) (int, int) {
+	var w, h C.int
+	C.SDL_QueryTexture(sdlTexture(img), nil, nil, &w, &h)
+	return int(w), int(h)
 }
 
-func LoadImage(path string) *Image {
+func (me *sdlBackend) LoadImage(path string) (*Image, error) {
 	var retval *Image
 	var texture *C.SDL_Texture
 
-	i := C.IMG_Load(C.CString(path))
-	if i == nil {
-		errlog.Println("Surface for", path, "loaded nil")
-		return nil
+	if renderer == nil {
+		return nil, &Error{Kind: ErrNoRenderer, Op: "LoadImage", Path: path}
 	}
 
-	if renderer == nil {
-		errlog.Println("Cannot load image because renderer is nil")
-		return nil
+	i := C.IMG_Load(C.CString(path))
+	if i == nil {
+		return nil, sdlLoadError(ErrDecode, "LoadImage", path)
 	}
+	defer C.SDL_FreeSurface(i)
 
 	runMainOp(func() {
 		texture = C.SDL_CreateTextureFromSurface(renderer, i)
 	})
 
 	if texture == nil {
-		errlog.Println("Texture for", path, "loaded nil")
-		return nil
+		return nil, sdlError(ErrTexture, "LoadImage", path)
 	}
 	retval = new(Image)
-	retval.surface = texture
-	C.SDL_FreeSurface(i)
-	return retval
+	retval.Texture = texture
+	retval.Width = int(i.w)
+	retval.Height = int(i.h)
+	return retval, nil
 }
 
-func FreeImage(img *Image) {
-	C.SDL_DestroyTexture(img.surface)
+func (me *sdlBackend) CreateTarget(w, h int) (*Image, error) {
+	if renderer == nil {
+		return nil, &Error{Kind: ErrNoRenderer, Op: "CreateTarget"}
+	}
+	var texture *C.SDL_Texture
+	runMainOp(func() {
+		texture = C.SDL_CreateTexture(renderer, C.SDL_PIXELFORMAT_RGBA8888, C.SDL_TEXTUREACCESS_TARGET, C.int(w), C.int(h))
+	})
+	if texture == nil {
+		return nil, sdlError(ErrTexture, "CreateTarget", "")
+	}
+	C.SDL_SetTextureBlendMode(texture, C.SDL_BLENDMODE_BLEND)
+	retval := new(Image)
+	retval.Texture = texture
+	retval.Width = w
+	retval.Height = h
+	me.DrawTo(retval, func() {
+		me.Clear(Color{})
+	})
+	return retval, nil
 }
 
-func ResizeAngleOf(image *Image, angle float64, width, height int) *Image {
-	img := image.surface
-	if image.W() == 0 || image.H() == 0 {
-		return nil
+func (me *sdlBackend) FreeImage(img *Image) {
+	C.SDL_DestroyTexture(sdlTexture(img))
+}
+
+//Textures cannot be read directly, so the image is copied into a target to read it, resampled in Go, and
+//uploaded into a new texture.
+func (me *sdlBackend) ResizeAngleOf(img *Image, angle float64, width, height, filter int) (*Image, error) {
+	if renderer == nil {
+		return nil, &Error{Kind: ErrNoRenderer, Op: "ResizeAngleOf"}
+	}
+	w, h := me.TextureSize(img)
+	if w == 0 || h == 0 {
+		return nil, &Error{Kind: ErrTexture, Op: "ResizeAngleOf", Msg: "image is empty"}
+	}
+	if width <= 0 || height <= 0 {
+		return nil, &Error{Kind: ErrTexture, Op: "ResizeAngleOf", Msg: "size must be positive"}
+	}
+	target, err := me.CreateTarget(w, h)
+	if err != nil {
+		return nil, err
+	}
+	defer me.FreeImage(target)
+	var pix *image.RGBA
+	me.DrawTo(target, func() {
+		var mode C.SDL_BlendMode
+		tex := sdlTexture(img)
+		C.SDL_GetTextureBlendMode(tex, &mode)
+		C.SDL_SetTextureBlendMode(tex, C.SDL_BLENDMODE_NONE)
+		C.SDL_RenderCopy(renderer, tex, nil, nil)
+		C.SDL_SetTextureBlendMode(tex, mode)
+		pix, err = me.ReadPixels(nil)
+	})
+	if err != nil {
+		return nil, &Error{Kind: ErrTexture, Op: "ResizeAngleOf", Msg: err.Error()}
+	}
+	//SDL's pixels are not premultiplied, which resampling needs to keep transparent colors from bleeding in
+	src := image.NewRGBA(pix.Rect)
+	draw.Draw(src, src.Rect, &image.NRGBA{Pix: pix.Pix, Stride: pix.Stride, Rect: pix.Rect}, image.Point{}, draw.Src)
+	out := resample(src, angle, width, height, filter)
+	up := image.NewNRGBA(out.Rect)
+	draw.Draw(up, up.Rect, out, image.Point{}, draw.Src)
+	var texture *C.SDL_Texture
+	runMainOp(func() {
+		texture = C.SDL_CreateTexture(renderer, C.SDL_PIXELFORMAT_RGBA32, C.SDL_TEXTUREACCESS_STATIC, C.int(up.Rect.Dx()), C.int(up.Rect.Dy()))
+		if texture != nil {
+			C.SDL_SetTextureBlendMode(texture, C.SDL_BLENDMODE_BLEND)
+			C.SDL_UpdateTexture(texture, nil, unsafe.Pointer(&up.Pix[0]), C.int(up.Stride))
+		}
+	})
+	if texture == nil {
+		return nil, sdlError(ErrTexture, "ResizeAngleOf", "")
 	}
 	retval := new(Image)
-	retval.Width = width
-	retval.Height = height
-	retval.surface = img
-	return retval
+	retval.Texture = texture
+	retval.Width = up.Rect.Dx()
+	retval.Height = up.Rect.Dy()
+	return retval, nil
 }
 
 //TEXT HANDLING
 
-type Font struct {
-	font *C.TTF_Font
+func ttfFont(font *Font) *C.TTF_Font {
+	f, _ := font.Face.(*C.TTF_Font)
+	return f
 }
 
-func LoadFont(path string, size int) *Font {
+func (me *sdlBackend) LoadFont(path string, size int) (*Font, error) {
+	f := C.TTF_OpenFont(C.CString(path), C.int(size))
+	if f == nil {
+		return nil, sdlLoadError(ErrDecode, "LoadFont", path)
+	}
 	font := new(Font)
-	font.font = C.TTF_OpenFont(C.CString(path), C.int(size))
-	return font
+	font.Face = f
+	return font, nil
 }
 
-func FreeFont(val *Font) {
-	C.TTF_CloseFont(val.font)
+func (me *sdlBackend) FreeFont(val *Font) {
+	C.TTF_CloseFont(ttfFont(val))
 }
 
-func (me *Font) WriteTo(text string, t *Image, c Color) bool {
-	sur := C.TTF_RenderText_Blended(me.font, C.CString(text), c.toSDL_Color())
+func (me *sdlBackend) WriteText(font *Font, text string, t *Image, c Color) error {
+	sur := C.TTF_RenderText_Blended(ttfFont(font), C.CString(text), c.toSDL_Color())
+	if sur == nil {
+		return sdlError(ErrText, "WriteText", "")
+	}
+	defer C.SDL_FreeSurface(sur)
 
+	var tex *C.SDL_Texture
 	runMainOp(func() {
-		t.surface = C.SDL_CreateTextureFromSurface(renderer, sur)
+		tex = C.SDL_CreateTextureFromSurface(renderer, sur)
 	})
+	if tex == nil {
+		return sdlError(ErrTexture, "WriteText", "")
+	}
+	t.Texture = tex
 
 	var w, h C.int
-	C.SDL_QueryTexture(t.surface, nil, nil, &w, &h)
+	C.SDL_QueryTexture(tex, nil, nil, &w, &h)
 	t.Width = int(w)
 	t.Height = int(h)
-	return t.surface != nil
+	return nil
 }
 
 //GFX HANDLING
 
-//Pushes a viewport to limit the drawing space to the given bounds within the current drawing space.
-func SetClipRect(x, y, w, h int) {
-	y *= -1
+//Limits drawing to the given bounds, measured like the Headless Backend's from the top left corner of the
+//drawing target. A negative width or height means the whole target.
+func (me *sdlBackend) SetClipRect(x, y, w, h int) {
+	if w < 0 || h < 0 {
+		C.SDL_RenderSetClipRect(renderer, nil)
+		return
+	}
 	r := sdl_Rect(x, y, w, h)
 	C.SDL_RenderSetClipRect(renderer, &r)
 }
 
-func FillRoundedRect(x, y, w, h, radius int, c Color) {
+func (me *sdlBackend) SetPaint(paint image.Image) {
+	me.paint = paint
+}
+
+//Multiplying and screening are only expressible in SDL's blend factors for premultiplied colors, as
+//src*dst + dst*(1-srcAlpha) and src + dst*(1-src), so shapes are drawn with premultiplied colors in those
+//modes. Images, whose colors are not premultiplied, are drawn by imageBlend instead, which matches them
+//only where the images are opaque.
+func (me *sdlBackend) SetBlendMode(mode int) {
+	me.mode = mode
+	switch mode {
+	case BlendMode_Add:
+		me.blend = C.SDL_BLENDMODE_ADD
+	case BlendMode_Modulate:
+		me.blend = C.SDL_BLENDMODE_MOD
+	case BlendMode_Multiply:
+		me.blend = C.SDL_ComposeCustomBlendMode(C.SDL_BLENDFACTOR_DST_COLOR, C.SDL_BLENDFACTOR_ONE_MINUS_SRC_ALPHA, C.SDL_BLENDOPERATION_ADD, C.SDL_BLENDFACTOR_ZERO, C.SDL_BLENDFACTOR_ONE, C.SDL_BLENDOPERATION_ADD)
+	case BlendMode_Screen:
+		me.blend = C.SDL_ComposeCustomBlendMode(C.SDL_BLENDFACTOR_ONE, C.SDL_BLENDFACTOR_ONE_MINUS_SRC_COLOR, C.SDL_BLENDOPERATION_ADD, C.SDL_BLENDFACTOR_ZERO, C.SDL_BLENDFACTOR_ONE, C.SDL_BLENDOPERATION_ADD)
+	case BlendMode_None:
+		me.blend = C.SDL_BLENDMODE_NONE
+	default:
+		me.mode = BlendMode_Alpha
+		me.blend = C.SDL_BLENDMODE_BLEND
+	}
+	me.imageBlend = me.blend
+	switch me.mode {
+	case BlendMode_Multiply:
+		me.imageBlend = C.SDL_BLENDMODE_MUL
+	case BlendMode_Screen:
+		me.imageBlend = C.SDL_ComposeCustomBlendMode(C.SDL_BLENDFACTOR_SRC_ALPHA, C.SDL_BLENDFACTOR_ONE_MINUS_SRC_COLOR, C.SDL_BLENDOPERATION_ADD, C.SDL_BLENDFACTOR_ZERO, C.SDL_BLENDFACTOR_ONE, C.SDL_BLENDOPERATION_ADD)
+	}
+	C.SDL_SetRenderDrawBlendMode(renderer, me.blend)
+}
+
+//Reports whether colors must be premultiplied by their alpha to be drawn by the blend mode.
+func (me *sdlBackend) premultiplied() bool {
+	return me.mode == BlendMode_Multiply || me.mode == BlendMode_Screen
+}
+
+//Sets the color rects are filled with, premultiplied if the blend mode needs it.
+func (me *sdlBackend) setDrawColor(c Color) {
+	if me.premultiplied() {
+		a := uint32(c.Alpha)
+		c = Color{uint8(uint32(c.Red) * a / 255), uint8(uint32(c.Green) * a / 255), uint8(uint32(c.Blue) * a / 255), c.Alpha}
+	}
+	C.SDL_SetRenderDrawColor(renderer, C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha))
+}
+
+func (me *sdlBackend) SetImageColor(c Color) {
+	me.imageColor = c
+}
+
+//Applies the image color to the texture for a draw, returning a function that puts back the texture's
+//own color and alpha, as a texture is shared by every Image loaded from the same file.
+func (me *sdlBackend) modulate(tex *C.SDL_Texture) func() {
+	var r, g, b, a C.Uint8
+	C.SDL_GetTextureColorMod(tex, &r, &g, &b)
+	C.SDL_GetTextureAlphaMod(tex, &a)
+	c := me.imageColor
+	C.SDL_SetTextureColorMod(tex, C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue))
+	C.SDL_SetTextureAlphaMod(tex, C.Uint8(c.Alpha))
+	return func() {
+		C.SDL_SetTextureColorMod(tex, r, g, b)
+		C.SDL_SetTextureAlphaMod(tex, a)
+	}
+}
+
+//Reports whether a shape must be drawn from its mask, as SDL_gfx draws only with solid colors,
+//and blends only by alpha.
+func (me *sdlBackend) masked(fill bool) bool {
+	return me.mode != BlendMode_Alpha || (fill && me.paint != nil)
+}
+
+//Returns the colors to fill with: the paint if one is set, or else the given color.
+func (me *sdlBackend) source(c Color) image.Image {
+	if me.paint != nil {
+		return me.paint
+	}
+	return image.NewUniform(c.toNRGBA())
+}
+
+func (me *sdlBackend) FillRoundedRect(x, y, w, h, radius int, c Color) {
+	if me.masked(true) {
+		me.drawMask(&roundedRectMask{image.Rect(x, y, x+w+1, y+h+1), radius}, me.source(c))
+		return
+	}
 	C.roundedBoxRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(x+w), C.Sint16(y+h), C.Sint16(radius), C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha))
+	me.restoreBlendMode()
+}
+
+//Borders wider than 1 pixel are filled by their spans, as SDL_gfx only draws 1 pixel wide borders.
+func (me *sdlBackend) DrawRoundedRect(x, y, w, h, radius, width int, c Color) {
+	if width <= 1 && me.masked(false) {
+		me.drawMask(newRoundedRectRing(image.Rect(x, y, x+w+1, y+h+1), radius, 1), image.NewUniform(c.toNRGBA()))
+		return
+	}
+	if width <= 1 {
+		C.roundedRectangleRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(x+w), C.Sint16(y+h), C.Sint16(radius), C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha))
+		me.restoreBlendMode()
+		return
+	}
+	me.fillSpans(maskSpans(newRoundedRectRing(image.Rect(x, y, x+w+1, y+h+1), radius, width)), c)
+}
+
+//Thick lines are not antialiased, as SDL_gfx has no antialiased thick lines.
+func (me *sdlBackend) DrawLine(x1, y1, x2, y2, width int, c Color, antialias bool) {
+	if me.masked(false) {
+		var mask image.Image
+		if width <= 1 && !antialias {
+			mask = lineMask(x1, y1, x2, y2, outputBounds())
+		} else {
+			mask = thickLineMask(x1, y1, x2, y2, max(width, 1), antialias, outputBounds())
+		}
+		me.drawMask(mask, image.NewUniform(c.toNRGBA()))
+		return
+	}
+	r, g, b, a := C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha)
+	switch {
+	case width > 1:
+		C.thickLineRGBA(renderer, C.Sint16(x1), C.Sint16(y1), C.Sint16(x2), C.Sint16(y2), C.Uint8(width), r, g, b, a)
+	case antialias:
+		C.aalineRGBA(renderer, C.Sint16(x1), C.Sint16(y1), C.Sint16(x2), C.Sint16(y2), r, g, b, a)
+	default:
+		C.lineRGBA(renderer, C.Sint16(x1), C.Sint16(y1), C.Sint16(x2), C.Sint16(y2), r, g, b, a)
+	}
+	me.restoreBlendMode()
+}
+
+//Antialiased ellipses are filled, then given an antialiased outline, as SDL_gfx has no antialiased fills.
+func (me *sdlBackend) FillEllipse(x, y, rx, ry int, c Color, antialias bool) {
+	if me.masked(true) {
+		me.drawMask(filledEllipseMask(x, y, rx, ry, antialias), me.source(c))
+		return
+	}
+	r, g, b, a := C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha)
+	C.filledEllipseRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(rx), C.Sint16(ry), r, g, b, a)
+	if antialias {
+		C.aaellipseRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(rx), C.Sint16(ry), r, g, b, a)
+	}
+	me.restoreBlendMode()
+}
+
+func (me *sdlBackend) DrawEllipse(x, y, rx, ry int, c Color, antialias bool) {
+	if me.masked(false) {
+		me.drawMask(ellipseMask(x, y, rx, ry, antialias), image.NewUniform(c.toNRGBA()))
+		return
+	}
+	r, g, b, a := C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha)
+	if antialias {
+		C.aaellipseRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(rx), C.Sint16(ry), r, g, b, a)
+	} else {
+		C.ellipseRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(rx), C.Sint16(ry), r, g, b, a)
+	}
+	me.restoreBlendMode()
+}
+
+//Arcs are never antialiased, as SDL_gfx has no antialiased arcs.
+func (me *sdlBackend) DrawArc(x, y, radius, start, end int, c Color, antialias bool) {
+	if me.masked(false) {
+		me.drawMask(arcMask(x, y, radius, start, end, false), image.NewUniform(c.toNRGBA()))
+		return
+	}
+	C.arcRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(radius), C.Sint16(start), C.Sint16(end), C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha))
+	me.restoreBlendMode()
+}
+
+func (me *sdlBackend) FillPolygon(points []image.Point, rule int, c Color, antialias bool) {
+	me.FillPath([][]PointF{pixelCenters(points)}, rule, c, antialias)
+}
+
+//Fills the path by its spans, as SDL_gfx only fills polygons by the even-odd rule.
+//Antialiased paths are drawn through their coverage mask instead, as outlining them would blend their
+//edges twice and draw the edges that cross their insides.
+func (me *sdlBackend) FillPath(contours [][]PointF, rule int, c Color, antialias bool) {
+	if antialias || me.paint != nil {
+		me.drawMask(pathMask(contours, rule, antialias, outputBounds()), me.source(c))
+		return
+	}
+	me.fillSpans(pathSpans(contours, rule, outputBounds()), c)
 }
 
-func FillRect(x, y, w, h int, c Color) {
+func (me *sdlBackend) fillSpans(spans []image.Rectangle, c Color) {
+	if len(spans) == 0 {
+		return
+	}
+	rects := make([]C.SDL_Rect, len(spans))
+	for i, s := range spans {
+		rects[i] = sdl_Rect(s.Min.X, s.Min.Y, s.Dx(), s.Dy())
+	}
+	me.setDrawColor(c)
+	C.SDL_RenderFillRects(renderer, &rects[0], C.int(len(rects)))
+}
+
+//Draws the source through the mask by drawing them into a texture, for the paints and blend modes
+//SDL_gfx cannot draw with. The pixels and the texture are kept to be reused by the next mask.
+func (me *sdlBackend) drawMask(mask, src image.Image) {
+	r := mask.Bounds().Intersect(outputBounds())
+	if r.Empty() {
+		return
+	}
+	tex := me.maskTexture(r.Dx(), r.Dy())
+	if tex == nil {
+		return
+	}
+	n := r.Dx() * r.Dy()
+	if cap(me.maskPix) < 4*n {
+		me.maskPix = make([]uint8, 4*n)
+		me.maskSrc = &image.RGBA{Pix: make([]uint8, 4*n)}
+		me.maskCover = &image.Alpha{Pix: make([]uint8, n)}
+	}
+	srcPix := &image.RGBA{Pix: me.maskSrc.Pix[:4*n], Stride: 4 * r.Dx(), Rect: r}
+	cover := &image.Alpha{Pix: me.maskCover.Pix[:n], Stride: r.Dx(), Rect: r}
+	pix := me.maskPix[:4*n]
+	draw.Draw(srcPix, r, src, r.Min, draw.Src)
+	maskAlpha(cover, mask)
+	for i, m := range cover.Pix {
+		s, d := srcPix.Pix[4*i:4*i+4], pix[4*i:4*i+4]
+		if me.premultiplied() {
+			for c := 0; c < 4; c++ {
+				d[c] = uint8(uint32(s[c]) * uint32(m) / 255)
+			}
+			continue
+		}
+		//the texture's colors are not premultiplied
+		d[0], d[1], d[2] = 0, 0, 0
+		if s[3] != 0 {
+			d[0], d[1], d[2] = uint8(uint32(s[0])*255/uint32(s[3])), uint8(uint32(s[1])*255/uint32(s[3])), uint8(uint32(s[2])*255/uint32(s[3]))
+		}
+		if me.mode == BlendMode_Modulate {
+			//modulating ignores alpha, so uncovered pixels are white to leave what is under them as it was
+			for c := 0; c < 3; c++ {
+				d[c] = uint8(255 - (255-uint32(d[c]))*uint32(m)/255)
+			}
+			d[3] = 255
+		} else {
+			d[3] = uint8(uint32(s[3]) * uint32(m) / 255)
+		}
+	}
+	area := sdl_Rect(0, 0, r.Dx(), r.Dy())
+	C.SDL_UpdateTexture(tex, &area, unsafe.Pointer(&pix[0]), C.int(4*r.Dx()))
+	C.SDL_SetTextureBlendMode(tex, me.blend)
+	if me.mode != BlendMode_None {
+		dest := sdl_Rect(r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+		C.SDL_RenderCopy(renderer, tex, &area, &dest)
+		return
+	}
+	//copying replaces what is under the uncovered pixels too, so only the covered rows are copied
+	for _, s := range maskSpans(cover) {
+		src := sdl_Rect(s.Min.X-r.Min.X, s.Min.Y-r.Min.Y, s.Dx(), s.Dy())
+		dest := sdl_Rect(s.Min.X, s.Min.Y, s.Dx(), s.Dy())
+		C.SDL_RenderCopy(renderer, tex, &src, &dest)
+	}
+}
+
+//Returns the streaming texture drawMask draws through, grown to at least the given size.
+func (me *sdlBackend) maskTexture(w, h int) *C.SDL_Texture {
+	if me.maskTex != nil && w <= me.maskTexSize.X && h <= me.maskTexSize.Y {
+		return me.maskTex
+	}
+	w, h = max(w, me.maskTexSize.X), max(h, me.maskTexSize.Y)
+	if me.maskTex != nil {
+		C.SDL_DestroyTexture(me.maskTex)
+	}
+	me.maskTex = C.SDL_CreateTexture(renderer, C.SDL_PIXELFORMAT_RGBA32, C.SDL_TEXTUREACCESS_STREAMING, C.int(w), C.int(h))
+	if me.maskTex == nil {
+		me.maskTexSize = image.Point{}
+		Logger().Warn("Could not create mask texture", "err", sdlError(ErrTexture, "SDL_CreateTexture", ""))
+		return nil
+	}
+	me.maskTexSize = image.Point{w, h}
+	return me.maskTex
+}
+
+//Pies are never antialiased, as SDL_gfx has no antialiased pies.
+func (me *sdlBackend) FillPie(x, y, radius, start, end int, c Color, antialias bool) {
+	if me.masked(true) {
+		me.drawMask(pieMask(x, y, radius, start, end, antialias), me.source(c))
+		return
+	}
+	C.filledPieRGBA(renderer, C.Sint16(x), C.Sint16(y), C.Sint16(radius), C.Sint16(start), C.Sint16(end), C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha))
+	me.restoreBlendMode()
+}
+
+//SDL_gfx turns blending off for opaque colors, so it must be turned back on after drawing with it.
+func (me *sdlBackend) restoreBlendMode() {
+	C.SDL_SetRenderDrawBlendMode(renderer, me.blend)
+}
+
+func (me *sdlBackend) FillRect(x, y, w, h int, c Color) {
+	if me.paint != nil {
+		me.drawMask(image.Rect(x, y, x+w, y+h), me.paint)
+		return
+	}
 	var rect C.SDL_Rect
 	rect.x = C.int(x)
 	rect.y = C.int(y)
 	rect.w = C.int(w)
 	rect.h = C.int(h)
-	C.SDL_SetRenderDrawColor(renderer, C.Uint8(c.Red), C.Uint8(c.Green), C.Uint8(c.Blue), C.Uint8(c.Alpha))
+	me.setDrawColor(c)
 	C.SDL_RenderFillRect(renderer, &rect)
 }
 
 //Draws the image at the given coordinates.
-func DrawImage(img *Image, destX, destY, srcX, srcY, srcW, srcH int) {
+func (me *sdlBackend) DrawImage(img *Image, destX, destY, srcX, srcY, srcW, srcH int) {
 	var src, dest C.SDL_Rect
 	dest.x = C.int(destX)
 	dest.y = C.int(destY)
@@ -276,154 +798,122 @@ func DrawImage(img *Image, destX, destY, srcX, srcY, srcW, srcH int) {
 	src.y = C.int(srcY)
 	src.w = C.int(srcW)
 	src.h = C.int(srcH)
-	C.SDL_SetTextureAlphaMod(img.surface, 255)
-	C.SDL_RenderCopy(renderer, img.surface, &src, &dest)
+	defer me.modulate(sdlTexture(img))()
+	C.SDL_SetTextureBlendMode(sdlTexture(img), me.imageBlend)
+	C.SDL_RenderCopy(renderer, sdlTexture(img), &src, &dest)
+}
+
+//Draws the quad as two textured triangles, which needs SDL 2.0.18 or newer.
+func (me *sdlBackend) DrawImageQuad(img *Image, srcX, srcY, srcW, srcH int, quad [4]PointF) {
+	w, h := me.TextureSize(img)
+	if w == 0 || h == 0 {
+		return
+	}
+	tex := [4]PointF{
+		{float64(srcX), float64(srcY)},
+		{float64(srcX + srcW), float64(srcY)},
+		{float64(srcX + srcW), float64(srcY + srcH)},
+		{float64(srcX), float64(srcY + srcH)},
+	}
+	var verts [4]C.SDL_Vertex
+	for i := range verts {
+		verts[i].position.x = C.float(quad[i].X)
+		verts[i].position.y = C.float(quad[i].Y)
+		verts[i].color = C.SDL_Color{255, 255, 255, 255}
+		verts[i].tex_coord.x = C.float(tex[i].X / float64(w))
+		verts[i].tex_coord.y = C.float(tex[i].Y / float64(h))
+	}
+	indices := [6]C.int{0, 1, 2, 0, 2, 3}
+	defer me.modulate(sdlTexture(img))()
+	C.SDL_SetTextureBlendMode(sdlTexture(img), me.imageBlend)
+	C.SDL_RenderGeometry(renderer, sdlTexture(img), &verts[0], 4, &indices[0], 6)
+}
+
+func (me *sdlBackend) DrawImageEx(img *Image, srcX, srcY, srcW, srcH, destX, destY, destW, destH, flip int, angle float64, pivotX, pivotY int) {
+	src := sdl_Rect(srcX, srcY, srcW, srcH)
+	dest := sdl_Rect(destX, destY, destW, destH)
+	pivot := C.SDL_Point{C.int(pivotX), C.int(pivotY)}
+	var f C.SDL_RendererFlip = C.SDL_FLIP_NONE
+	if flip&Flip_Horizontal != 0 {
+		f |= C.SDL_FLIP_HORIZONTAL
+	}
+	if flip&Flip_Vertical != 0 {
+		f |= C.SDL_FLIP_VERTICAL
+	}
+	defer me.modulate(sdlTexture(img))()
+	C.SDL_SetTextureBlendMode(sdlTexture(img), me.imageBlend)
+	C.SDL_RenderCopyEx(renderer, sdlTexture(img), &src, &dest, C.double(angle), &pivot, f)
 }
 
 //EVENT HANDLING
 
-func HandleEvents() {
-	for running := true; running; {
-		var e C.SDL_Event
-		C.SDL_WaitEvent(&e)
-		switch C.eventType(&e) {
-		case C.SDL_QUIT:
-			inputChan <- e
-			running = false
-		case C.SDL_KEYDOWN, C.SDL_KEYUP, C.SDL_MOUSEBUTTONDOWN, C.SDL_MOUSEBUTTONUP, C.SDL_MOUSEWHEEL:
-			inputChan <- e
-		case Event_MainOpEvent:
-			(<-mainOpChan)()
-		case Event_DrawEvent:
-			drawFunc()
-			C.SDL_RenderPresent(renderer)
-			drawComplete <- nil
-		}
+//Converts an SDL window event, reporting false for those starfish does not pass on.
+func windowEvent(e *C.SDL_Event) (WindowEvent, bool) {
+	var we WindowEvent
+	switch C.eventWindowEvent(e) {
+	case C.SDL_WINDOWEVENT_SIZE_CHANGED:
+		we.Type = Window_Resized
+		var w, h C.int
+		C.SDL_GetRendererOutputSize(renderer, &w, &h)
+		we.Width, we.Height = int(w), int(h)
+	case C.SDL_WINDOWEVENT_FOCUS_GAINED:
+		we.Type = Window_FocusGained
+	case C.SDL_WINDOWEVENT_FOCUS_LOST:
+		we.Type = Window_FocusLost
+	case C.SDL_WINDOWEVENT_MINIMIZED:
+		we.Type = Window_Minimized
+	case C.SDL_WINDOWEVENT_MAXIMIZED:
+		we.Type = Window_Maximized
+	case C.SDL_WINDOWEVENT_RESTORED:
+		we.Type = Window_Restored
+	case C.SDL_WINDOWEVENT_ENTER:
+		we.Type = Window_MouseEnter
+	case C.SDL_WINDOWEVENT_LEAVE:
+		we.Type = Window_MouseLeave
+	case C.SDL_WINDOWEVENT_EXPOSED:
+		we.Type = Window_Exposed
+	default:
+		return we, false
 	}
+	return we, true
 }
 
-func HandleInput() {
+func (me *sdlBackend) HandleEvents() {
 	for running := true; running; {
-		e := <-inputChan
+		var e C.SDL_Event
+		C.SDL_WaitEvent(&e)
 		switch C.eventType(&e) {
 		case C.SDL_QUIT:
-			go QuitFunc()
+			PostEvent(QuitEvent{})
 			running = false
-		case C.SDL_KEYDOWN:
-			{
-				var ke KeyEvent
-				ke.Key = int(C.eventKey(&e))
-				go KeyDown(ke)
-			}
-		case C.SDL_KEYUP:
-			{
-				var ke KeyEvent
-				ke.Key = int(C.eventKey(&e))
-				go KeyUp(ke)
-			}
+		case C.SDL_KEYDOWN, C.SDL_KEYUP:
+			var ke KeyEvent
+			ke.Key = int(C.eventKey(&e))
+			ke.Pressed = C.eventType(&e) == C.SDL_KEYDOWN
+			PostEvent(ke)
 		case C.SDL_MOUSEWHEEL:
 			var mwe MouseWheelEvent
 			var x, y C.int
 			C.SDL_GetMouseState(&x, &y)
-			mwe.X = int(x)
-			mwe.Y = int(y)
+			mwe.X, mwe.Y = toPixels(x, y)
 			mwe.Up = C.eventMouseWheelY(&e) > 0
-			go MouseWheelFunc(mwe)
-		case C.SDL_MOUSEBUTTONDOWN:
-			x := int(C.eventMouseX(&e))
-			y := int(C.eventMouseY(&e))
-			var me MouseEvent
-			me.X = x
-			me.Y = y
-			me.Button = int(C.eventMouseButton(&e))
-			go MouseButtonDown(me)
-		case C.SDL_MOUSEBUTTONUP:
-			var me MouseEvent
-			me.Button = int(C.eventMouseButton(&e))
-			me.X = int(C.eventMouseX(&e))
-			me.Y = int(C.eventMouseY(&e))
-			me.Button = int(C.eventMouseButton(&e))
-			go MouseButtonUp(me)
+			PostEvent(mwe)
+		case C.SDL_MOUSEBUTTONDOWN, C.SDL_MOUSEBUTTONUP:
+			var mbe MouseEvent
+			mbe.X, mbe.Y = toPixels(C.eventMouseX(&e), C.eventMouseY(&e))
+			mbe.Button = int(C.eventMouseButton(&e))
+			mbe.Pressed = C.eventType(&e) == C.SDL_MOUSEBUTTONDOWN
+			PostEvent(mbe)
+		case C.SDL_WINDOWEVENT:
+			if we, ok := windowEvent(&e); ok {
+				PostEvent(we)
+			}
+		case Event_MainOpEvent:
+			(<-mainOpChan)()
+		case Event_DrawEvent:
+			(<-drawChan)()
+			C.SDL_RenderPresent(renderer)
+			drawComplete <- nil
 		}
 	}
 }
-
-//KEY DEFINITIONS
-
-const (
-	Key_a int = C.SDLK_a
-	Key_b     = C.SDLK_b
-	Key_c     = C.SDLK_c
-	Key_d     = C.SDLK_d
-	Key_e     = C.SDLK_e
-	Key_f     = C.SDLK_f
-	Key_g     = C.SDLK_g
-	Key_h     = C.SDLK_h
-	Key_i     = C.SDLK_i
-	Key_j     = C.SDLK_j
-	Key_k     = C.SDLK_k
-	Key_l     = C.SDLK_l
-	Key_m     = C.SDLK_m
-	Key_n     = C.SDLK_n
-	Key_o     = C.SDLK_o
-	Key_p     = C.SDLK_p
-	Key_q     = C.SDLK_q
-	Key_r     = C.SDLK_r
-	Key_s     = C.SDLK_s
-	Key_t     = C.SDLK_t
-	Key_u     = C.SDLK_u
-	Key_v     = C.SDLK_v
-	Key_w     = C.SDLK_w
-	Key_x     = C.SDLK_x
-	Key_y     = C.SDLK_y
-	Key_z     = C.SDLK_z
-
-	Key_0 = C.SDLK_0
-	Key_1 = C.SDLK_1
-	Key_2 = C.SDLK_2
-	Key_3 = C.SDLK_3
-	Key_4 = C.SDLK_4
-	Key_5 = C.SDLK_5
-	Key_6 = C.SDLK_6
-	Key_7 = C.SDLK_7
-	Key_8 = C.SDLK_8
-	Key_9 = C.SDLK_9
-
-	Key_Colon        = C.SDLK_COLON
-	Key_SemiColon    = C.SDLK_SEMICOLON
-	Key_LessThan     = C.SDLK_LESS
-	Key_Equals       = C.SDLK_EQUALS
-	Key_GreaterThan  = C.SDLK_GREATER
-	Key_QuestionMark = C.SDLK_QUESTION
-	Key_At           = C.SDLK_AT
-	Key_LeftBracket  = C.SDLK_LEFTBRACKET
-	Key_RightBracket = C.SDLK_RIGHTBRACKET
-	Key_Caret        = C.SDLK_CARET
-	Key_Underscore   = C.SDLK_UNDERSCORE
-	Key_BackQuote    = C.SDLK_BACKQUOTE
-	Key_Backspace    = C.SDLK_BACKSPACE
-	Key_Tab          = C.SDLK_TAB
-	Key_Enter        = C.SDLK_RETURN
-	Key_Pause        = C.SDLK_PAUSE
-	Key_Escape       = C.SDLK_ESCAPE
-	Key_Space        = C.SDLK_SPACE
-	Key_ExclaimMark  = C.SDLK_EXCLAIM
-	Key_DoubleQuote  = C.SDLK_QUOTEDBL
-	Key_Hash         = C.SDLK_HASH
-	Key_Dollar       = C.SDLK_DOLLAR
-	Key_LeftParen    = C.SDLK_LEFTPAREN
-	Key_RightParen   = C.SDLK_RIGHTPAREN
-	Key_Asterisk     = C.SDLK_ASTERISK
-	Key_Plus         = C.SDLK_PLUS
-	Key_Comma        = C.SDLK_COMMA
-	Key_Minus        = C.SDLK_MINUS
-	Key_Period       = C.SDLK_PERIOD
-	Key_Slash        = C.SDLK_SLASH
-	Key_Delete       = C.SDLK_DELETE
-	Key_Up           = C.SDLK_UP
-	Key_Down         = C.SDLK_DOWN
-	Key_Left         = C.SDLK_LEFT
-	Key_Right        = C.SDLK_RIGHT
-	Key_RCtrl        = C.SDLK_RCTRL
-	Key_LCtrl        = C.SDLK_LCTRL
-)
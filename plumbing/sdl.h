@@ -16,7 +16,12 @@
 #include <SDL2/SDL.h>
 #include <SDL2/SDL_image.h>
 
-SDL_Window* openDisplay(int w, int h, int full);
+// SDL_RenderGeometry needs 2.0.18, which also has SDL_BLENDMODE_MUL and SDL_PIXELFORMAT_RGBA32
+#if !SDL_VERSION_ATLEAST(2, 0, 18)
+#error "starfish needs SDL 2.0.18 or later"
+#endif
+
+SDL_Window* openDisplay(int x, int y, int w, int h, Uint32 flags);
 
 void closeDisplay();
 
@@ -35,3 +40,5 @@ int eventMouseX(SDL_Event *e);
 int eventMouseY(SDL_Event *e);
 
 int eventMouseWheelY(SDL_Event *e);
+
+int eventWindowEvent(SDL_Event *e);
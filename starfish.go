@@ -19,15 +19,8 @@ import (
 	p "github.com/gtalent/starfish/plumbing"
 )
 
-var running = false
-
 //Blocks until CloseDisplay is called, regardless of whether or not OpenDisplay has been called.
+//Nothing is drawn; use gfx.Main or gfx.Run to draw.
 func Main() {
-	go func() {
-		for running {
-			p.Draw()
-			//time.Sleep(time.Duration(drawInterval))
-		}
-	}()
 	p.HandleEvents()
 }